@@ -0,0 +1,157 @@
+package itscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type productTypesCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[Language]productTypesCacheEntry
+}
+
+type productTypesCacheEntry struct {
+	productTypes []ProductType
+	fetchedAt    time.Time
+}
+
+// cachedProductTypes returns the product types in the standard
+// representation, served from the product-type cache while it is fresh.
+func (its *ITScopeCommunicator) cachedProductTypes(ctx context.Context) ([]ProductType, error) {
+	if its.typeCache == nil {
+		return its.GetAllProductTypes(ctx)
+	}
+
+	language := its.languageFor(ctx)
+	its.typeCache.mu.Lock()
+	entry, ok := its.typeCache.entries[language]
+	its.typeCache.mu.Unlock()
+	if ok && its.clock.Now().Sub(entry.fetchedAt) < its.typeCache.ttl {
+		its.stats.cacheHits.Add(1)
+		return entry.productTypes, nil
+	}
+	its.stats.cacheMisses.Add(1)
+
+	productTypes, err := its.GetAllProductTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	its.typeCache.mu.Lock()
+	its.typeCache.entries[language] = productTypesCacheEntry{productTypes: productTypes, fetchedAt: its.clock.Now()}
+	its.typeCache.mu.Unlock()
+
+	return productTypes, nil
+}
+
+// WarmCaches fills the product-type cache, if WithProductTypeCache is set,
+// and the reference-data cache for the language of ctx, so the first real
+// request does not pay for the downloads.
+func (its *ITScopeCommunicator) WarmCaches(ctx context.Context) error {
+	if its.typeCache != nil {
+		if _, err := its.cachedProductTypes(ctx); err != nil {
+			return fmt.Errorf("WarmCaches: %w", err)
+		}
+	}
+	if _, err := its.GetReferenceData(ctx); err != nil {
+		return fmt.Errorf("WarmCaches: %w", err)
+	}
+
+	return nil
+}
+
+type responseCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	maxStale  time.Duration
+	entries   map[string]responseCacheEntry
+	lastSweep time.Time
+}
+
+type responseCacheEntry struct {
+	products *ProductsContainer
+	storedAt time.Time
+}
+
+// cachedResponse returns a copy of the cached search result for signature.
+// With allowStale, entries past their TTL but within the maximum stale age
+// are returned as well, marked as Stale.
+func (its *ITScopeCommunicator) cachedResponse(signature string, allowStale bool) (*ProductsContainer, bool) {
+	if its.responses == nil {
+		return nil, false
+	}
+
+	its.responses.mu.Lock()
+	entry, ok := its.responses.entries[signature]
+	its.responses.mu.Unlock()
+
+	age := its.clock.Now().Sub(entry.storedAt)
+	if ok && age < its.responses.ttl {
+		its.stats.cacheHits.Add(1)
+		products := *entry.products
+		return &products, true
+	} else if ok && allowStale && age < its.responses.ttl+its.responses.maxStale {
+		its.stats.cacheHits.Add(1)
+		products := *entry.products
+		products.Stale = true
+		return &products, true
+	}
+	if !allowStale {
+		its.stats.cacheMisses.Add(1)
+	}
+
+	return nil, false
+}
+
+// servesStale reports whether a search that failed with err may fall back to
+// a stale response: only for upstream failures worth retrying, never for
+// cancellation, shutdown or a 404.
+func servesStale(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrShutdown) {
+		return false
+	}
+	var status UnexpectedStatusCodeError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500 || status.StatusCode == http.StatusTooManyRequests
+	}
+	var transport *url.Error
+	return errors.As(err, &transport)
+}
+
+func (its *ITScopeCommunicator) storeResponse(signature string, products *ProductsContainer) {
+	if its.responses == nil {
+		return
+	}
+
+	now := its.clock.Now()
+	its.responses.mu.Lock()
+	defer its.responses.mu.Unlock()
+
+	its.responses.entries[signature] = responseCacheEntry{products: products, storedAt: now}
+	if now.Sub(its.responses.lastSweep) < its.responses.ttl+its.responses.maxStale {
+		return
+	}
+	for key, entry := range its.responses.entries {
+		if now.Sub(entry.storedAt) >= its.responses.ttl+its.responses.maxStale {
+			delete(its.responses.entries, key)
+		}
+	}
+	its.responses.lastSweep = now
+}
+
+type referenceDataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[Language]referenceDataCacheEntry
+}
+
+type referenceDataCacheEntry struct {
+	data      *ReferenceData
+	fetchedAt time.Time
+}
@@ -0,0 +1,171 @@
+package itscope
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetAllProductTypesSingleflight(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		<-release
+		return jsonResponse(request, http.StatusOK, `{"productType":[{"id":"1"}]}`), nil
+	}), WithSingleflight())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			productTypes, err := its.GetAllProductTypes(context.Background())
+			if err == nil && len(productTypes) != 1 {
+				t.Errorf("got %d product types, want 1", len(productTypes))
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d round trips, want 1", got)
+	}
+}
+
+func TestServeStaleOnErrorOnlyForUpstreamFailures(t *testing.T) {
+	var status atomic.Int64
+	status.Store(http.StatusOK)
+	newCommunicator := func() *ITScopeCommunicator {
+		status.Store(http.StatusOK)
+		its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+			if err := request.Context().Err(); err != nil {
+				return nil, err
+			}
+			return jsonResponse(request, int(status.Load()), `{"product":[{"puid":"1"}]}`), nil
+		}), WithNoRetry(), WithServeStaleOnError(time.Hour))
+		if _, err := its.GetProductsFromQuery(context.Background(), "manufacturer=HP"); err != nil {
+			t.Fatal(err)
+		}
+		return its
+	}
+
+	its := newCommunicator()
+	status.Store(http.StatusServiceUnavailable)
+	products, err := its.GetProductsFromQuery(context.Background(), "manufacturer=HP")
+	if err != nil || !products.Stale {
+		t.Errorf("got %v, %v on 503, want the stale products", products, err)
+	}
+
+	its = newCommunicator()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := its.GetProductsFromQuery(ctx, "manufacturer=HP"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v with a cancelled context, want context.Canceled", err)
+	}
+
+	its = newCommunicator()
+	if err := its.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := its.GetProductsFromQuery(context.Background(), "manufacturer=HP"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("got error %v after Close, want ErrShutdown", err)
+	}
+}
+
+// testSharedCallOutlivesCancelledCaller starts a shared call, lets a second
+// caller join it and cancels the first: the first must return right away and
+// the second must still get the result of the single round trip.
+func testSharedCallOutlivesCancelledCaller(t *testing.T, body string, call func(ctx context.Context, its *ITScopeCommunicator) error) {
+	var calls atomic.Int64
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		entered <- struct{}{}
+		select {
+		case <-release:
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		}
+		return jsonResponse(request, http.StatusOK, body), nil
+	}), WithSingleflight())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() { first <- call(ctx, its) }()
+	<-entered
+	second := make(chan error, 1)
+	go func() { second <- call(context.Background(), its) }()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-first:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled caller got %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled caller did not return")
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller got %v, want the shared result", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d round trips, want 1", got)
+	}
+}
+
+func TestSharedSearchOutlivesCancelledCaller(t *testing.T) {
+	testSharedCallOutlivesCancelledCaller(t, `{"product":[{"puid":"1"}]}`, func(ctx context.Context, its *ITScopeCommunicator) error {
+		_, err := its.GetProductsFromQuery(ctx, "manufacturer=HP")
+		return err
+	})
+}
+
+func TestSharedProductTypesOutliveCancelledCaller(t *testing.T) {
+	testSharedCallOutlivesCancelledCaller(t, `{"productType":[{"id":"1"}]}`, func(ctx context.Context, its *ITScopeCommunicator) error {
+		_, err := its.GetAllProductTypes(ctx)
+		return err
+	})
+}
+
+func TestRealtimeSearchesSkipCacheStats(t *testing.T) {
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		return jsonResponse(request, http.StatusOK, `{"product":[{"puid":"1"}]}`), nil
+	}), WithResponseCache(time.Hour))
+
+	for i := 0; i < 3; i++ {
+		if _, err := its.GetProductsFromQuery(context.Background(), "puid=1", SearchRealtime(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := its.Stats(); stats.CacheHits != 0 || stats.CacheMisses != 0 {
+		t.Errorf("realtime searches counted %d hits and %d misses, want none", stats.CacheHits, stats.CacheMisses)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := its.GetProductsFromQuery(context.Background(), "puid=1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := its.Stats(); stats.CacheHits != 1 || stats.CacheMisses != 1 {
+		t.Errorf("cached searches counted %d hits and %d misses, want 1 and 1", stats.CacheHits, stats.CacheMisses)
+	}
+}
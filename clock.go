@@ -0,0 +1,20 @@
+package itscope
+
+import "time"
+
+// Clock abstracts time for retry delays and cache expiry, so tests can
+// replace the real clock with a controllable one.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
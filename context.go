@@ -0,0 +1,58 @@
+package itscope
+
+import "context"
+
+type contextKey int
+
+const (
+	notFoundAsErrorKey contextKey = iota
+	requestHeadersKey
+	languageKey
+	operationKey
+	priorityKey
+)
+
+// ContextWithNotFoundAsError overrides WithNotFoundAsError for calls made
+// with the returned context.
+func ContextWithNotFoundAsError(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, notFoundAsErrorKey, enabled)
+}
+
+func (its *ITScopeCommunicator) notFoundAsError(ctx context.Context) bool {
+	if enabled, ok := ctx.Value(notFoundAsErrorKey).(bool); ok {
+		return enabled
+	}
+	return its.notFoundErrors
+}
+
+// ContextWithRequestHeaders adds headers to the requests made with the
+// returned context, after the communicator-wide WithRequestHeaders.
+func ContextWithRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, requestHeadersKey, headers)
+}
+
+// ContextWithLanguage overrides the communicator's language for calls made
+// with the returned context, without touching SetLanguage.
+func ContextWithLanguage(ctx context.Context, language Language) context.Context {
+	return context.WithValue(ctx, languageKey, language)
+}
+
+func (its *ITScopeCommunicator) languageFor(ctx context.Context) Language {
+	if language, ok := ctx.Value(languageKey).(Language); ok {
+		return language
+	}
+	return its.language
+}
+
+// ContextWithPriority marks the calls made with the returned context as high
+// priority. They use the limiter set with WithPriorityRateLimit instead of
+// queueing behind other requests, but still respect the limits set with
+// WithEndpointRateLimit; without that option the mark has no effect.
+func ContextWithPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, priorityKey, true)
+}
+
+func isPriority(ctx context.Context) bool {
+	priority, _ := ctx.Value(priorityKey).(bool)
+	return priority
+}
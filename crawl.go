@@ -0,0 +1,103 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CrawlState is the serializable position of a paged crawl. The zero Cursor
+// starts at the first page; Done is set once the last page was delivered.
+type CrawlState struct {
+	Query  string `json:"query"`
+	Cursor string `json:"cursor"`
+	Done   bool   `json:"done"`
+}
+
+// Crawl tracks a crawl started with ResumeCrawl.
+type Crawl struct {
+	mu    sync.Mutex
+	state CrawlState
+	err   error
+}
+
+// State returns the checkpoint to resume from. It only advances once all
+// products of a page were received, so products of a page interrupted midway
+// are delivered again after resuming.
+func (c *Crawl) State() CrawlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Err returns the error that ended the crawl, if any, or ErrResultsTruncated
+// if the crawl completed but ITScope truncated the result. It is valid once
+// the product channel is closed.
+func (c *Crawl) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// ResumeCrawl pages through state.Query from state.Cursor and sends every
+// product on the returned channel, which is closed when the crawl ends or ctx
+// is cancelled. Persist Crawl.State to resume after an interruption.
+func (its *ITScopeCommunicator) ResumeCrawl(ctx context.Context, state CrawlState) (<-chan Product, *Crawl, error) {
+	if _, _, err := decodeCursor(state.Cursor); err != nil {
+		return nil, nil, err
+	}
+
+	products := make(chan Product)
+	crawl := &Crawl{state: state}
+	if state.Done {
+		close(products)
+		return products, crawl, nil
+	}
+	ctx, done, err := its.beginOperation(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go func() {
+		defer done()
+		defer close(products)
+		cursor := state.Cursor
+		truncated := false
+		for {
+			container, next, err := its.GetProductsPageCursor(ctx, state.Query, cursor)
+			if err != nil {
+				crawl.fail(err)
+				return
+			}
+			truncated = truncated || container.Truncated
+			for _, product := range container.Product {
+				select {
+				case products <- product:
+				case <-ctx.Done():
+					crawl.fail(ctx.Err())
+					return
+				}
+			}
+
+			crawl.mu.Lock()
+			crawl.state.Cursor = next
+			crawl.state.Done = next == ""
+			crawl.mu.Unlock()
+			if next == "" {
+				if truncated {
+					crawl.fail(fmt.Errorf("ResumeCrawl: %w", ErrResultsTruncated))
+				}
+				return
+			}
+			cursor = next
+		}
+	}()
+
+	return products, crawl, nil
+}
+
+func (c *Crawl) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
@@ -0,0 +1,91 @@
+package itscope
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldChange describes a product field that differs between two versions of
+// a product. Field is the JSON name of the field.
+type FieldChange struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// ProductChange is a product present in both containers with differing
+// fields.
+type ProductChange struct {
+	Puid    string
+	Old     Product
+	New     Product
+	Changes []FieldChange
+}
+
+// ContainerDiff holds the products added, removed and changed between two
+// search results, matched by Puid.
+type ContainerDiff struct {
+	Added   []Product
+	Removed []Product
+	Changed []ProductChange
+}
+
+// Diff returns the JSON-mapped fields of p that differ in other. Extra and
+// ResolvedAccessories are not compared.
+func (p *Product) Diff(other *Product) []FieldChange {
+	changes := make([]FieldChange, 0)
+	oldValue := reflect.ValueOf(p).Elem()
+	newValue := reflect.ValueOf(other).Elem()
+	t := oldValue.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "resolvedAccessories" {
+			continue
+		}
+		oldField, newField := oldValue.Field(i).Interface(), newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			changes = append(changes, FieldChange{Field: name, Old: oldField, New: newField})
+		}
+	}
+
+	return changes
+}
+
+// DiffContainers compares two search results. Added and Changed follow the
+// order of newer, Removed the order of older. Either container may be nil.
+func DiffContainers(older, newer *ProductsContainer) ContainerDiff {
+	var oldProducts, newProducts []Product
+	if older != nil {
+		oldProducts = older.Product
+	}
+	if newer != nil {
+		newProducts = newer.Product
+	}
+
+	oldByPuid := make(map[string]int, len(oldProducts))
+	for i := range oldProducts {
+		oldByPuid[oldProducts[i].Puid] = i
+	}
+	newByPuid := make(map[string]struct{}, len(newProducts))
+
+	diff := ContainerDiff{}
+	for i := range newProducts {
+		product := &newProducts[i]
+		newByPuid[product.Puid] = struct{}{}
+		index, ok := oldByPuid[product.Puid]
+		if !ok {
+			diff.Added = append(diff.Added, *product)
+			continue
+		}
+		if changes := oldProducts[index].Diff(product); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, ProductChange{Puid: product.Puid, Old: oldProducts[index], New: *product, Changes: changes})
+		}
+	}
+	for _, product := range oldProducts {
+		if _, ok := newByPuid[product.Puid]; !ok {
+			diff.Removed = append(diff.Removed, product)
+		}
+	}
+
+	return diff
+}
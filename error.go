@@ -1,33 +1,232 @@
 package itscope
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strings"
 )
 
 var (
-	ErrNotFound = &UnexpectedStatusCodeError{StatusCode: http.StatusNotFound}
+	ErrNotFound              = &UnexpectedStatusCodeError{StatusCode: http.StatusNotFound, Message: "404 Not Found"}
+	ErrRetryBudgetExhausted  = errors.New("retry budget exhausted")
+	ErrUnexpectedContentType = errors.New("unexpected content type")
+	ErrQueryTooLong          = errors.New("query too long")
+	ErrRealtimeTimeout       = errors.New("distributors did not answer in time")
+	ErrDegraded              = errors.New("product types unavailable, results are unfiltered")
+	ErrRateLimited           = errors.New("rate limit would delay the request too long")
+	ErrReservedHeader        = errors.New("header is reserved")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrInvalidFilter         = errors.New("filter contains \";\" or \"=\"")
+	ErrShutdown              = errors.New("communicator is shut down")
+	ErrResultsTruncated      = errors.New("query matches more products than ITScope returns")
 )
 
 type UnexpectedStatusCodeError struct {
 	Message    string
 	StatusCode int
+	Body       string
+	// API is the error reported by ITScope in the body, nil if the body
+	// is not an ITScope error payload.
+	API *APIError
 }
 
 func (e UnexpectedStatusCodeError) Error() string {
+	if e.API != nil {
+		return e.Message + ": " + e.API.Error()
+	}
+	if e.Body != "" {
+		return e.Message + ": " + e.Body
+	}
 	return e.Message
 }
 
-func (e *UnexpectedStatusCodeError) Is(tgt error) bool {
-	target, ok := tgt.(*UnexpectedStatusCodeError)
-	if !ok {
+func (e UnexpectedStatusCodeError) Is(tgt error) bool {
+	switch target := tgt.(type) {
+	case *UnexpectedStatusCodeError:
+		return target != nil && e.StatusCode == target.StatusCode
+	case UnexpectedStatusCodeError:
+		return e.StatusCode == target.StatusCode
+	default:
 		return false
 	}
-	return e.StatusCode == target.StatusCode
 }
 
 func NewUnexpectedStatusCodeError(response *http.Response) UnexpectedStatusCodeError {
+	body := readErrorBody(response)
 	return UnexpectedStatusCodeError{
 		StatusCode: response.StatusCode,
 		Message:    response.Status,
+		Body:       body,
+		API:        parseAPIError(body),
+	}
+}
+
+// APIError is the error payload ITScope sends with some 4xx responses.
+type APIError struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Details []string `json:"details"`
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if e.Code != "" {
+		message = e.Code + ": " + message
+	}
+	if len(e.Details) > 0 {
+		message += " (" + strings.Join(e.Details, "; ") + ")"
+	}
+	return message
+}
+
+// parseAPIError decodes body as {"code", "message", "details"} object, plain
+// or wrapped in "error". Details may be a string or a list of strings.
+func parseAPIError(body string) *APIError {
+	type payload struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+	}
+	var wrapper struct {
+		payload
+		Error *payload `json:"error"`
+	}
+	if json.Unmarshal([]byte(body), &wrapper) != nil {
+		return nil
+	}
+	p := wrapper.payload
+	if wrapper.Error != nil {
+		p = *wrapper.Error
+	}
+	if p.Message == "" {
+		return nil
 	}
+
+	apiError := &APIError{Code: rawString(p.Code), Message: p.Message}
+	var details []string
+	if json.Unmarshal(p.Details, &details) == nil {
+		apiError.Details = details
+	} else if detail := rawString(p.Details); detail != "" {
+		apiError.Details = []string{detail}
+	}
+
+	return apiError
+}
+
+// rawString returns a JSON string or number as string, "" otherwise.
+func rawString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// readErrorBody returns the start of an error response body. Bodies the
+// transport did not decompress itself, e.g. because Accept-Encoding was set
+// explicitly, are gunzipped here.
+func readErrorBody(response *http.Response) string {
+	if response.Body == nil {
+		return ""
+	}
+
+	var body io.Reader = response.Body
+	if !response.Uncompressed && strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return ""
+		}
+		defer reader.Close()
+		body = reader
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(body, 512))
+	return strings.TrimSpace(strings.ToValidUTF8(string(data), ""))
+}
+
+type DecodeError struct {
+	Endpoint string
+	Snippet  string
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("could not decode response of %s: %v (body: %q)", e.Endpoint, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// statusCode returns the status code of the UnexpectedStatusCodeError in the
+// chain of err, or 0 if there is none.
+func statusCode(err error) int {
+	var statusErr UnexpectedStatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	var statusErrPtr *UnexpectedStatusCodeError
+	if errors.As(err, &statusErrPtr) && statusErrPtr != nil {
+		return statusErrPtr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err means the requested resource does not exist.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsRateLimited reports whether err was caused by ITScope or the local rate
+// limiter rejecting the request for being over the rate limit.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited) || statusCode(err) == http.StatusTooManyRequests
+}
+
+// IsAuth reports whether ITScope rejected the credentials.
+func IsAuth(err error) bool {
+	code := statusCode(err)
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// IsRetryable reports whether repeating the request later may succeed:
+// timeouts, rate limiting, server errors and network failures.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if IsRateLimited(err) || errors.Is(err, ErrRealtimeTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	switch code := statusCode(err); {
+	case code == http.StatusRequestTimeout:
+		return true
+	case code >= 500 && code != http.StatusNotImplemented:
+		return true
+	case code != 0:
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
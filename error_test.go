@@ -0,0 +1,60 @@
+package itscope
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewUnexpectedStatusCodeErrorGzippedBody(t *testing.T) {
+	const message = `{"message":"invalid parameter 'sort'"}`
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(message))
+	_ = writer.Close()
+
+	for name, response := range map[string]*http.Response{
+		"compressed": {
+			StatusCode: http.StatusBadRequest,
+			Status:     "400 Bad Request",
+			Header:     http.Header{"Content-Encoding": {"gzip"}},
+			Body:       io.NopCloser(bytes.NewReader(compressed.Bytes())),
+		},
+		"decompressed by transport": {
+			StatusCode:   http.StatusBadRequest,
+			Status:       "400 Bad Request",
+			Header:       http.Header{},
+			Body:         io.NopCloser(strings.NewReader(message)),
+			Uncompressed: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := NewUnexpectedStatusCodeError(response)
+			if err.Body != message {
+				t.Errorf("got body %q, want %q", err.Body, message)
+			}
+			if err.API == nil || err.API.Message != "invalid parameter 'sort'" {
+				t.Errorf("got API error %+v", err.API)
+			}
+		})
+	}
+}
+
+func TestGetProductByPUIDNotFoundMessage(t *testing.T) {
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		return jsonResponse(request, http.StatusOK, `{"product":[]}`), nil
+	}))
+
+	_, err := its.GetProductByPUID(context.Background(), "123")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v, want ErrNotFound", err)
+	}
+	if got, want := err.Error(), "GetProductByPUID: 404 Not Found: 123"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
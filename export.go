@@ -0,0 +1,69 @@
+package itscope
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DefaultCSVColumns are written by WriteProductsCSV if no columns are given.
+var DefaultCSVColumns = []string{"puid", "productNameWithManufacturer", "manufacturerName", "manufacturerSKU", "price", "currencyCode", "stock"}
+
+// WriteProductsCSV writes products as CSV with a header row. Columns are the
+// JSON names of Product fields, matched case-insensitively, e.g. "puid",
+// "ean", "manufacturerName", "manufacturerSKU", "productNameWithManufacturer",
+// "price", "currencyCode", "stock" or "stockStatus". List fields such as
+// "supplierItems" are written as JSON. The output can be read back with
+// FormatCSV decoding.
+func WriteProductsCSV(w io.Writer, products []Product, columns []string) error {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	indices := make([]int, len(columns))
+	for i, column := range columns {
+		index, ok := productJSONFields[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return fmt.Errorf("csv: unknown product column %q", column)
+		}
+		indices[i] = index
+	}
+
+	writer := csv.NewWriter(w)
+	err := writer.Write(columns)
+	if err != nil {
+		return fmt.Errorf("csv: could not write header: %w", err)
+	}
+
+	record := make([]string, len(columns))
+	for i := range products {
+		value := reflect.ValueOf(&products[i]).Elem()
+		for j, index := range indices {
+			record[j], err = csvValue(value.Field(index))
+			if err != nil {
+				return fmt.Errorf("csv: column %q of product %s: %w", columns[j], products[i].Puid, err)
+			}
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return fmt.Errorf("csv: could not write product %s: %w", products[i].Puid, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(field reflect.Value) (string, error) {
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		data, err := json.Marshal(field.Interface())
+		return string(data), err
+	default:
+		return fmt.Sprint(field.Interface()), nil
+	}
+}
@@ -0,0 +1,35 @@
+package itscope
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWriteProductsCSVRoundTrip(t *testing.T) {
+	products := []Product{
+		{
+			Puid:             "1",
+			ManufacturerName: "HP, Inc.",
+			Price:            "199,90",
+			FeatureBullets:   []string{"fast", "quiet \"really\""},
+			SupplierItems:    []SupplierItem{{SupplierID: "42", Price: "189,90", Stock: "3"}},
+			Accessories:      []Accessory{{ReferencedProductID: "2", TypeID: "ACC"}},
+		},
+		{Puid: "2", ManufacturerName: "Dell"},
+	}
+	columns := []string{"puid", "manufacturerName", "price", "featureBullets", "supplierItems", "accessories"}
+
+	var buffer bytes.Buffer
+	if err := WriteProductsCSV(&buffer, products, columns); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ProductsContainer
+	if err := decodeCSV(&buffer, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded.Product, products) {
+		t.Errorf("round trip changed the products:\n got %+v\nwant %+v", decoded.Product, products)
+	}
+}
@@ -0,0 +1,51 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// GetFavorites returns the products on the account's favorites list. An
+// account without favorites yields an empty result.
+func (its *ITScopeCommunicator) GetFavorites(ctx context.Context) ([]Product, error) {
+	format := its.format
+	request, err := its.newRequest(ctx, http.MethodGet, its.buildURL("favorites/favorite."+string(format), nil), nil, format)
+	if err != nil {
+		return nil, fmt.Errorf("GetFavorites: %w", err)
+	}
+
+	response, err := its.do(request, EndpointFavorites, "GetFavorites")
+	if err != nil {
+		return nil, fmt.Errorf("GetFavorites: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return []Product{}, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GetFavorites: %w", NewUnexpectedStatusCodeError(response))
+	}
+
+	var favorites FavoritesContainer
+	err = its.decode(response, format, &favorites)
+	if err != nil {
+		return nil, fmt.Errorf("GetFavorites: %w", err)
+	}
+
+	ids := make([]string, 0, len(favorites.Favorite))
+	for _, favorite := range favorites.Favorite {
+		ids = append(ids, favorite.Puid)
+	}
+	if len(ids) == 0 {
+		return []Product{}, nil
+	}
+
+	products, err := its.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("GetFavorites: %w", err)
+	}
+
+	return products, nil
+}
@@ -0,0 +1,183 @@
+package itscope
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decoder turns a response body of a specific Format into v.
+type Decoder func(r io.Reader, v any) error
+
+var formatMediaTypes = map[Format]string{
+	FormatJSON: "application/json",
+	FormatXML:  "application/xml",
+	FormatCSV:  "text/csv",
+}
+
+func defaultDecoders() map[Format]Decoder {
+	return map[Format]Decoder{
+		FormatJSON: decodeJSON,
+		FormatXML:  decodeXML,
+		FormatCSV:  decodeCSV,
+	}
+}
+
+func (f Format) mediaType() string {
+	if mediaType, ok := formatMediaTypes[f]; ok {
+		return mediaType
+	}
+	return "application/" + string(f)
+}
+
+// accepts reports whether a response with the given Content-Type can be
+// decoded as f. Formats without a known media type accept everything.
+func (f Format) accepts(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch f {
+	case FormatJSON:
+		return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+	case FormatXML:
+		return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+	case FormatCSV:
+		return mediaType == "text/csv" || mediaType == "application/csv" || mediaType == "text/plain"
+	default:
+		return true
+	}
+}
+
+func decodeJSON(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func decodeXML(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// decodeCSV fills the first slice field of the struct v points to with one
+// element per CSV row. Header columns are matched against the json tags of
+// the element type; list and nested columns are decoded from JSON as written
+// by WriteProductsCSV, unknown columns are ignored.
+func decodeCSV(r io.Reader, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot decode into %T", v)
+	}
+
+	var list reflect.Value
+	for i := 0; i < target.Elem().NumField(); i++ {
+		field := target.Elem().Field(i)
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct && field.CanSet() {
+			list = field
+			break
+		}
+	}
+	if !list.IsValid() {
+		return fmt.Errorf("csv: %T has no list field", v)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("csv: could not read header: %w", err)
+	}
+
+	columns := make([]int, len(header))
+	fields := jsonFieldIndex(list.Type().Elem())
+	for i, name := range header {
+		index, ok := fields[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			index = -1
+		}
+		columns[i] = index
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("csv: could not read record: %w", err)
+		}
+
+		item := reflect.New(list.Type().Elem()).Elem()
+		for i, value := range record {
+			if i >= len(columns) || columns[i] < 0 {
+				continue
+			}
+			err = setCSVValue(item.Field(columns[i]), value)
+			if err != nil {
+				return fmt.Errorf("csv: column %q: %w", header[i], err)
+			}
+		}
+		list.Set(reflect.Append(list, item))
+	}
+
+	return nil
+}
+
+func jsonFieldIndex(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[strings.ToLower(name)] = i
+	}
+	return fields
+}
+
+func setCSVValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		if value == "" {
+			return nil
+		}
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(number)
+	case reflect.Float64, reflect.Float32:
+		if value == "" {
+			return nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(number)
+	case reflect.Bool:
+		if value == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		if value == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(value), field.Addr().Interface())
+	}
+	return nil
+}
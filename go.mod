@@ -4,6 +4,7 @@ go 1.22
 
 require (
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sync v0.10.0
 	golang.org/x/time v0.8.0
 )
 
@@ -0,0 +1,49 @@
+package itscope
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// instantClock lets retry delays pass immediately.
+type instantClock struct{}
+
+func (instantClock) Now() time.Time {
+	return time.Now()
+}
+
+func (instantClock) After(time.Duration) <-chan time.Time {
+	c := make(chan time.Time, 1)
+	c <- time.Now()
+	return c
+}
+
+func newTestCommunicator(transport http.RoundTripper, opts ...Option) *ITScopeCommunicator {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	opts = append([]Option{WithoutRateLimit(), WithClock(instantClock{}), WithLogger(logger)}, opts...)
+	its := New("test", "user", "secret", German, opts...)
+	its.client.Transport = transport
+	return its
+}
+
+func jsonResponse(request *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    request,
+	}
+}
@@ -0,0 +1,130 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+)
+
+// HydrateOptions selects the relationships GetHydratedProduct resolves.
+type HydrateOptions struct {
+	Accessories        bool
+	BundleComponents   bool
+	ServiceAccessories bool
+	// Depth is the number of levels resolved below the product; accessories
+	// of accessories need 2. Values below 1 mean 1.
+	Depth int
+}
+
+// HydratedProduct is a product with its resolved relationships, ready to be
+// serialized in one piece.
+type HydratedProduct struct {
+	Product            Product            `json:"product"`
+	Accessories        []*HydratedProduct `json:"accessories,omitempty"`
+	BundleComponents   []*HydratedProduct `json:"bundleComponents,omitempty"`
+	ServiceAccessories []Product          `json:"serviceAccessories,omitempty"`
+}
+
+type hydrator struct {
+	its      *ITScopeCommunicator
+	options  HydrateOptions
+	resolved map[hydrateKey]*HydratedProduct
+}
+
+type hydrateKey struct {
+	puid  string
+	depth int
+}
+
+// GetHydratedProduct fetches the product with puid and resolves the
+// relationships selected in options. A product reached several times is
+// resolved once and shared; a product that refers back to one of its own
+// ancestors is left out of that branch.
+func (its *ITScopeCommunicator) GetHydratedProduct(ctx context.Context, puid string, options HydrateOptions) (*HydratedProduct, error) {
+	product, err := its.GetProductByPUID(ctx, puid)
+	if err != nil {
+		return nil, fmt.Errorf("GetHydratedProduct: %w", err)
+	}
+
+	options.Depth = max(options.Depth, 1)
+	h := &hydrator{its: its, options: options, resolved: make(map[hydrateKey]*HydratedProduct)}
+	hydrated, err := h.hydrate(ctx, *product, 0, map[string]struct{}{})
+	if err != nil {
+		return nil, fmt.Errorf("GetHydratedProduct: %w", err)
+	}
+
+	return hydrated, nil
+}
+
+func (h *hydrator) hydrate(ctx context.Context, product Product, depth int, ancestors map[string]struct{}) (*HydratedProduct, error) {
+	key := hydrateKey{puid: product.Puid, depth: depth}
+	if hydrated, ok := h.resolved[key]; ok {
+		return hydrated, nil
+	}
+	hydrated := &HydratedProduct{Product: product}
+	if depth >= h.options.Depth {
+		return hydrated, nil
+	}
+
+	ancestors[product.Puid] = struct{}{}
+	defer delete(ancestors, product.Puid)
+
+	if h.options.Accessories || h.options.ServiceAccessories {
+		accessories, err := h.its.GetProductAccessories(ctx, &product)
+		if err != nil {
+			return nil, err
+		}
+		if h.options.Accessories {
+			hydrated.Accessories, err = h.hydrateAll(ctx, accessories, depth+1, ancestors)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if h.options.ServiceAccessories {
+			hydrated.ServiceAccessories, err = h.serviceAccessories(ctx, accessories)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if h.options.BundleComponents {
+		components, err := h.its.GetBundleComponents(ctx, &product)
+		if err != nil {
+			return nil, err
+		}
+		hydrated.BundleComponents, err = h.hydrateAll(ctx, components, depth+1, ancestors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h.resolved[key] = hydrated
+	return hydrated, nil
+}
+
+// serviceAccessories keeps the accessories in the service product-type group,
+// the same as GetServiceTypeAccessoriesOfProduct without fetching them again.
+func (h *hydrator) serviceAccessories(ctx context.Context, accessories []Product) ([]Product, error) {
+	serviceTypes, ok, err := h.its.groupTypes(ctx, "GetHydratedProduct", ProductTypeGroupService)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return accessories, nil
+	}
+	return h.its.FilterProductsByTypeList(accessories, serviceTypes), nil
+}
+
+func (h *hydrator) hydrateAll(ctx context.Context, products []Product, depth int, ancestors map[string]struct{}) ([]*HydratedProduct, error) {
+	hydrated := make([]*HydratedProduct, 0, len(products))
+	for _, product := range products {
+		if _, ok := ancestors[product.Puid]; ok {
+			continue
+		}
+		child, err := h.hydrate(ctx, product, depth, ancestors)
+		if err != nil {
+			return nil, err
+		}
+		hydrated = append(hydrated, child)
+	}
+	return hydrated, nil
+}
@@ -0,0 +1,37 @@
+package itscope
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetHydratedProductFetchesAccessoriesOnce(t *testing.T) {
+	var searches int
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		if strings.Contains(request.URL.Path, "/producttypes/") {
+			return jsonResponse(request, http.StatusOK, `{"productType":[{"id":"SVC","productTypeGroup":{"id":"SSP"}},{"id":"HW","productTypeGroup":{"id":"HWA"}}]}`), nil
+		}
+		searches++
+		if searches == 1 {
+			return jsonResponse(request, http.StatusOK, `{"product":[{"puid":"1","accessories":[{"referencedProductId":"2"},{"referencedProductId":"3"}]}]}`), nil
+		}
+		return jsonResponse(request, http.StatusOK, `{"product":[{"puid":"2","productTypeId":"SVC"},{"puid":"3","productTypeId":"HW"}]}`), nil
+	}))
+
+	hydrated, err := its.GetHydratedProduct(context.Background(), "1", HydrateOptions{Accessories: true, ServiceAccessories: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if searches != 2 {
+		t.Errorf("made %d searches, want 2", searches)
+	}
+	if len(hydrated.Accessories) != 2 {
+		t.Errorf("got %d accessories, want 2", len(hydrated.Accessories))
+	}
+	if len(hydrated.ServiceAccessories) != 1 || hydrated.ServiceAccessories[0].Puid != "2" {
+		t.Errorf("got service accessories %v, want only puid 2", hydrated.ServiceAccessories)
+	}
+}
@@ -1,15 +1,23 @@
 package itscope
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 	"golang.org/x/time/rate"
 )
 
@@ -18,179 +26,1233 @@ type ITScopeCommunicator struct {
 	password    string
 	userAgent   string
 	language    Language
+	format      Format
+	decoders    map[Format]Decoder
 	client      *http.Client
 	CompanyName string
 	limiter     *rate.Limiter
+	limiters    map[Endpoint]*rate.Limiter
+	priority    *rate.Limiter
+	retryBudget *rate.Limiter
+	maxRetries  int
+	inflight    *singleflight.Group
+	clock       Clock
+	logger      logrus.FieldLogger
+	typeCache   *productTypesCache
+	responses   *responseCache
+	references  *referenceDataCache
+
+	bestEffortTypes bool
+	onDegraded      func(err error)
+	notFoundErrors  bool
+	maxWait         time.Duration
+	headers         map[string]string
+	appTag          string
+	apiVersion      string
+	queryRewriter   func(string) string
+	credentials     func() (username string, password string)
+	progress        func(read, total int64)
+	normalizeImages bool
+	distributors    map[string]struct{}
+	defaultTimeout  time.Duration
+	retryableStatus map[int]struct{}
+	retryDecode     bool
+
+	stats      stats
+	operations operationTracker
 }
 
-func New(companyName string, userName string, password string, language Language) *ITScopeCommunicator {
+func New(companyName string, userName string, password string, language Language, opts ...Option) *ITScopeCommunicator {
 	its := new(ITScopeCommunicator)
 	its.CompanyName = companyName
 	its.userAgent = its.CompanyName + "-ITS_ApiModule-0.1"
 	its.username = userName
 	its.password = password
 	its.language = language
+	its.format = FormatJSON
+	its.decoders = defaultDecoders()
 	its.client = &http.Client{}
 	its.limiter = rate.NewLimiter(rate.Limit(6), 6)
+	its.limiters = map[Endpoint]*rate.Limiter{
+		EndpointRealtime: rate.NewLimiter(rate.Limit(1), 1),
+	}
+	its.maxRetries = 2
+	its.apiVersion = "2.0"
+	its.references = &referenceDataCache{ttl: 24 * time.Hour, entries: make(map[Language]referenceDataCacheEntry)}
+	its.clock = realClock{}
+	its.logger = logrus.StandardLogger()
+
+	for _, opt := range opts {
+		opt(its)
+	}
+	for code := range its.retryableStatus {
+		if code < 100 || code > 599 {
+			its.logger.WithField("status", code).Warnln("Ignoring invalid HTTP status code in WithRetryableStatusCodes")
+			delete(its.retryableStatus, code)
+		}
+	}
 
 	return its
 }
 
-func (its *ITScopeCommunicator) SetLanguage(language Language) {
-	its.language = language
+// NewWithValidation is like New but fails if ITScope cannot be reached or
+// rejects the credentials.
+func NewWithValidation(ctx context.Context, companyName string, userName string, password string, language Language, opts ...Option) (*ITScopeCommunicator, error) {
+	its := New(companyName, userName, password, language, opts...)
+	err := its.Ping(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate credentials: %w", err)
+	}
+
+	return its, nil
+}
+
+// Ping sends a single, unretried search for a non-existent product to check
+// connectivity and credentials.
+func (its *ITScopeCommunicator) Ping(ctx context.Context) error {
+	ctx, done, err := its.beginOperation(ctx)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	defer done()
+	ctx, cancel := its.withDefaultTimeout(ctx)
+	defer cancel()
+
+	request, err := its.newRequest(ctx, http.MethodGet, its.buildURL("products/search/puid%3D0/standard.json", url.Values{"page": {"1"}}), nil, FormatJSON)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+
+	if err = its.waitAll(ctx, its.limitersFor(ctx, EndpointSearch)); err != nil {
+		return fmt.Errorf("limiter timeout: %w", err)
+	}
+	response, err := its.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return NewUnexpectedStatusCodeError(response)
+	}
+
+	return nil
+}
+
+func (its *ITScopeCommunicator) SetLanguage(language Language) {
+	its.language = language
+}
+
+// SetFormat selects the representation requested from ITScope. JSON is the
+// default; XML and CSV are decoded by the registered Decoder for the format.
+func (its *ITScopeCommunicator) SetFormat(format Format) {
+	its.format = format
+}
+
+// SetDecoder registers or replaces the Decoder used for format.
+func (its *ITScopeCommunicator) SetDecoder(format Format, decoder Decoder) {
+	its.decoders[format] = decoder
+}
+
+func (its *ITScopeCommunicator) decode(response *http.Response, format Format, v any) error {
+	decoder, ok := its.decoders[format]
+	if !ok {
+		return fmt.Errorf("no decoder registered for format %q", format)
+	}
+
+	body := bufio.NewReader(response.Body)
+	contentType := response.Header.Get("Content-Type")
+	if contentType != "" && !format.accepts(contentType) {
+		return fmt.Errorf("%w: %s returned %q, expected %s", ErrUnexpectedContentType, responsePath(response), contentType, format.mediaType())
+	} else if contentType == "" && format == FormatJSON && looksLikeMarkup(body) {
+		return fmt.Errorf("%w: %s returned markup, expected %s", ErrUnexpectedContentType, responsePath(response), format.mediaType())
+	}
+
+	snippet := &prefixBuffer{limit: 512}
+	err := decoder(io.TeeReader(body, snippet), v)
+	if err != nil {
+		return &DecodeError{
+			Endpoint: responsePath(response),
+			Snippet:  string(snippet.buf),
+			Err:      err,
+		}
+	}
+
+	return nil
+}
+
+// responsePath returns the path that response was requested from, or "" if
+// the transport did not set its request.
+func responsePath(response *http.Response) string {
+	if response.Request == nil || response.Request.URL == nil {
+		return ""
+	}
+	return response.Request.URL.Path
+}
+
+// looksLikeMarkup peeks at the first non-blank byte of body to detect HTML
+// or XML pages served in place of JSON.
+func looksLikeMarkup(body *bufio.Reader) bool {
+	for n := 1; n <= 512; n++ {
+		peek, err := body.Peek(n)
+		if len(peek) < n {
+			return false
+		}
+		switch peek[n-1] {
+		case ' ', '\t', '\r', '\n':
+			if err != nil {
+				return false
+			}
+			continue
+		case '<':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// prefixBuffer keeps the first limit bytes written to it.
+type prefixBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *prefixBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.buf); room > 0 {
+		b.buf = append(b.buf, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// requestSignature identifies a request by everything that influences its
+// response: method, URL, language and requested format.
+func requestSignature(request *http.Request) string {
+	canonical := *request.URL
+	canonical.RawQuery = canonical.Query().Encode()
+	return request.Method + " " + canonical.String() + " " + request.Header.Get("Accept-Language") + " " + request.Header.Get("Accept")
+}
+
+func (its *ITScopeCommunicator) authenticateRequest(request *http.Request, format Format) error {
+	username, password := its.username, its.password
+	if its.credentials != nil {
+		username, password = its.credentials()
+	}
+	if username == "" || password == "" {
+		return fmt.Errorf("no username or password set")
+	}
+
+	request.SetBasicAuth(username, password)
+	request.Header.Add("Accept", format.mediaType())
+	request.Header.Add("UserAgent", its.userAgent)
+	request.Header.Add("Accept-Language", string(its.languageFor(request.Context())))
+	if its.appTag != "" {
+		request.Header.Set(appTagHeader, its.appTag)
+	}
+
+	contextHeaders, _ := request.Context().Value(requestHeadersKey).(map[string]string)
+	for _, headers := range []map[string]string{its.headers, contextHeaders} {
+		for key, value := range headers {
+			if http.CanonicalHeaderKey(key) == "Authorization" {
+				return fmt.Errorf("%w: %s", ErrReservedHeader, key)
+			}
+			request.Header.Set(key, value)
+		}
+	}
+
+	return nil
+}
+
+// limitersFor returns the rate limiters a request to endpoint waits for: the
+// limiter of endpoint, or the shared limiter if the endpoint has none of its
+// own. Priority requests use the priority limiter, if one is configured, in
+// place of the shared limiter, but still wait for an endpoint limiter.
+func (its *ITScopeCommunicator) limitersFor(ctx context.Context, endpoint Endpoint) []*rate.Limiter {
+	limiter, ok := its.limiters[endpoint]
+	if its.priority != nil && isPriority(ctx) {
+		if ok {
+			return []*rate.Limiter{its.priority, limiter}
+		}
+		return []*rate.Limiter{its.priority}
+	}
+	if ok {
+		return []*rate.Limiter{limiter}
+	}
+	return []*rate.Limiter{its.limiter}
+}
+
+// waitAll waits for each of limiters in turn.
+func (its *ITScopeCommunicator) waitAll(ctx context.Context, limiters []*rate.Limiter) error {
+	for _, limiter := range limiters {
+		if err := its.wait(ctx, limiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wait blocks until limiter permits a request. If a maximum wait is
+// configured and the limiter would block longer, it returns ErrRateLimited
+// right away.
+func (its *ITScopeCommunicator) wait(ctx context.Context, limiter *rate.Limiter) error {
+	if its.maxWait <= 0 {
+		return limiter.Wait(ctx)
+	}
+
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return ErrRateLimited
+	}
+	delay := reservation.Delay()
+	if delay > its.maxWait {
+		reservation.Cancel()
+		return ErrRateLimited
+	} else if delay == 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	case <-its.clock.After(delay):
+		return nil
+	}
+}
+
+// do sends request, retrying transport errors and unexpected status codes.
+// Every attempt waits for the rate limiter of endpoint; every retry consumes
+// the retry budget when one is configured.
+func (its *ITScopeCommunicator) do(request *http.Request, endpoint Endpoint, operation string) (*http.Response, error) {
+	ctx, done, err := its.beginOperation(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := its.withDefaultTimeout(ctx)
+	response, err := its.doWithRetries(request.WithContext(ctx), endpoint, operation)
+	if err != nil {
+		cancel()
+		done()
+		return nil, err
+	}
+	response.Body = cancelOnClose{ReadCloser: response.Body, cancel: func() {
+		cancel()
+		done()
+	}}
+	if its.progress != nil && (endpoint == EndpointSearch || endpoint == EndpointProductTypes) {
+		response.Body = &progressBody{ReadCloser: response.Body, total: response.ContentLength, callback: its.progress}
+	}
+
+	return response, nil
+}
+
+// withDecodeRetries calls fetch, which requests and decodes a response, again
+// after a DecodeError if WithRetryOnDecodeError is set, up to the maximum
+// number of retries.
+func (its *ITScopeCommunicator) withDecodeRetries(ctx context.Context, operation string, fetch func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fetch()
+		var decodeErr *DecodeError
+		if err == nil || !its.retryDecode || attempt >= its.maxRetries || !errors.As(err, &decodeErr) {
+			return err
+		}
+		if its.retryBudget != nil && !its.retryBudget.Allow() {
+			return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		its.stats.retries.Add(1)
+		delay := 4 * time.Second
+		its.logger.WithFields(logrus.Fields{"operation": operation, "attempt": attempt + 1, "error": err.Error(), "delay": delay.String()}).Warnln("Error during " + operation + ", retrying...")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-its.clock.After(delay):
+		}
+	}
+}
+
+// withDefaultTimeout applies the timeout configured with WithDefaultTimeout
+// to contexts without a deadline.
+func (its *ITScopeCommunicator) withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || its.defaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, its.defaultTimeout)
+}
+
+// cancelOnClose releases the context of a request once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// progressBody reports the bytes read from a response body to callback.
+type progressBody struct {
+	io.ReadCloser
+	read     int64
+	total    int64
+	callback func(read, total int64)
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.read += int64(n)
+		b.callback(b.read, b.total)
+	}
+	return n, err
+}
+
+// shouldRetry reports whether a request that ended with response or err is
+// retried: transport errors always, statuses as set by
+// WithRetryableStatusCodes, by default anything but 2xx and 404.
+func (its *ITScopeCommunicator) shouldRetry(response *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if its.retryableStatus != nil {
+		_, ok := its.retryableStatus[response.StatusCode]
+		return ok
+	}
+	return (response.StatusCode < 200 || response.StatusCode > 299) && response.StatusCode != http.StatusNotFound
+}
+
+func (its *ITScopeCommunicator) doWithRetries(request *http.Request, endpoint Endpoint, operation string) (*http.Response, error) {
+	limiters := its.limitersFor(request.Context(), endpoint)
+	retries := its.maxRetries + 1
+	var response *http.Response
+	var err error
+	for attempt := 0; retries > 0; attempt++ {
+		if err = its.waitAll(request.Context(), limiters); err != nil {
+			return nil, fmt.Errorf("limiter timeout: %w", err)
+		}
+
+		if attempt > 0 && request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("could not rewind request body: %w", err)
+			}
+		}
+
+		response, err = its.client.Do(request)
+		if err != nil {
+			its.stats.recordResponse(0, err)
+		} else {
+			its.stats.recordResponse(response.StatusCode, nil)
+			response.Body = countingBody{ReadCloser: response.Body, counter: &its.stats.bytesRead}
+		}
+		if !its.shouldRetry(response, err) {
+			break
+		}
+
+		fields := logrus.Fields{"operation": operation, "attempt": attempt + 1}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = response.StatusCode
+		}
+
+		retries -= 1
+		if retries == 0 {
+			its.logger.WithFields(fields).Errorln("Error during " + operation + ", giving up")
+			break
+		}
+
+		if its.retryBudget != nil && !its.retryBudget.Allow() {
+			if err == nil {
+				err = NewUnexpectedStatusCodeError(response)
+				response.Body.Close()
+			}
+			return nil, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		if response != nil {
+			response.Body.Close()
+		}
+		its.stats.retries.Add(1)
+		delay := 4 * time.Second
+		fields["delay"] = delay.String()
+		its.logger.WithFields(fields).Warnln("Error during " + operation + ", retrying...")
+		select {
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		case <-its.clock.After(delay):
+		}
+	}
+
+	return response, err
+}
+
+func (its *ITScopeCommunicator) GetProductData(ctx context.Context, productSKU string) (*Product, error) {
+	productContainer, err := its.GetProductsFromQuery(ctx, "distpid="+productSKU)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve product data: %w", err)
+	}
+
+	if len(productContainer.Product) > 0 {
+		return &productContainer.Product[0], nil
+	} else {
+		return nil, nil
+	}
+}
+
+// GetProductDetail fetches a single product in the detail representation,
+// including its long texts in the communicator's language. Texts that are not
+// available in that language are left blank.
+func (its *ITScopeCommunicator) GetProductDetail(ctx context.Context, puid string) (*Product, error) {
+	productContainer, err := its.GetProductsFromQuery(ctx, "puid="+puid, SearchRepr(RepresentationDetail))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve product detail: %w", err)
+	}
+
+	if len(productContainer.Product) > 0 {
+		return &productContainer.Product[0], nil
+	}
+
+	return nil, nil
+}
+
+// GetAllProductTypes returns all product types. With WithSingleflight,
+// concurrent calls for the same language and options share one fetch.
+func (its *ITScopeCommunicator) GetAllProductTypes(ctx context.Context, opts ...ProductTypesOption) ([]ProductType, error) {
+	if its.inflight == nil {
+		return its.retrieveProductTypes(ctx, opts)
+	}
+
+	params := newProductTypesParams(opts)
+	key := "producttypes " + string(its.languageFor(ctx)) + " " + string(params.representation) + "." + string(its.format) + " " + strconv.FormatBool(params.includeInactive)
+	productTypes, err := its.shared(ctx, key, func(ctx context.Context) (any, error) {
+		return its.retrieveProductTypes(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return productTypes.([]ProductType), nil
+}
+
+func (its *ITScopeCommunicator) retrieveProductTypes(ctx context.Context, opts []ProductTypesOption) ([]ProductType, error) {
+	var productTypes []ProductType
+	err := its.withDecodeRetries(ctx, "GetAllProductTypes", func() (err error) {
+		productTypes, err = its.fetchProductTypes(ctx, opts)
+		return err
+	})
+	return productTypes, err
+}
+
+func (its *ITScopeCommunicator) fetchProductTypes(ctx context.Context, opts []ProductTypesOption) ([]ProductType, error) {
+	format := its.format
+	response, err := its.requestProductTypes(ctx, newProductTypesParams(opts), format)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound && its.notFoundAsError(ctx) {
+		return nil, fmt.Errorf("could not retrieve product types: %w", NewUnexpectedStatusCodeError(response))
+	} else if response.StatusCode == http.StatusNotFound {
+		return []ProductType{}, nil
+	}
+	var productTypes ProductTypesContainer
+	err = its.decode(response, format, &productTypes)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+	}
+
+	return productTypes.ProductTypes, nil
+}
+
+// GetAllProductTypesRaw returns the undecoded JSON product-types response,
+// e.g. to share it between processes. Decode it with DecodeProductTypes.
+func (its *ITScopeCommunicator) GetAllProductTypesRaw(ctx context.Context, opts ...ProductTypesOption) (json.RawMessage, error) {
+	response, err := its.requestProductTypes(ctx, newProductTypesParams(opts), FormatJSON)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound && its.notFoundAsError(ctx) {
+		return nil, fmt.Errorf("could not retrieve product types: %w", NewUnexpectedStatusCodeError(response))
+	} else if response.StatusCode == http.StatusNotFound {
+		return json.RawMessage(`{"productType":[]}`), nil
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+	}
+
+	return data, nil
+}
+
+// DecodeProductTypes decodes a JSON product-types response as returned by
+// GetAllProductTypesRaw.
+func (its *ITScopeCommunicator) DecodeProductTypes(data []byte) ([]ProductType, error) {
+	var productTypes ProductTypesContainer
+	err := json.Unmarshal(data, &productTypes)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode product types: %w", err)
+	}
+
+	return productTypes.ProductTypes, nil
+}
+
+// StreamProductTypes decodes the product types one at a time and passes each
+// to fn, keeping memory bounded for large responses. An error returned by fn
+// stops the stream and is returned.
+func (its *ITScopeCommunicator) StreamProductTypes(ctx context.Context, fn func(ProductType) error, opts ...ProductTypesOption) error {
+	response, err := its.requestProductTypes(ctx, newProductTypesParams(opts), FormatJSON)
+	if err != nil {
+		return fmt.Errorf("could not retrieve product types: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound && its.notFoundAsError(ctx) {
+		return fmt.Errorf("could not retrieve product types: %w", NewUnexpectedStatusCodeError(response))
+	} else if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	if err = expectDelim(decoder, '{'); err != nil {
+		return fmt.Errorf("could not stream product types: %w", err)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("could not stream product types: %w", err)
+		}
+		if key, _ := token.(string); key != "productType" {
+			var skip json.RawMessage
+			if err = decoder.Decode(&skip); err != nil {
+				return fmt.Errorf("could not stream product types: %w", err)
+			}
+			continue
+		}
+
+		if err = expectDelim(decoder, '['); err != nil {
+			return fmt.Errorf("could not stream product types: %w", err)
+		}
+		for decoder.More() {
+			var productType ProductType
+			if err = decoder.Decode(&productType); err != nil {
+				return fmt.Errorf("could not stream product types: %w", err)
+			}
+			if err = fn(productType); err != nil {
+				return err
+			}
+		}
+		if err = expectDelim(decoder, ']'); err != nil {
+			return fmt.Errorf("could not stream product types: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+// requestProductTypes returns the product-types response if its status is
+// 200 or 404.
+func (its *ITScopeCommunicator) requestProductTypes(ctx context.Context, params productTypesParams, format Format) (*http.Response, error) {
+	values := url.Values{}
+	if params.includeInactive {
+		values.Set("includeinactive", "true")
+	}
+	urlString := its.buildURL("products/producttypes/"+string(params.representation)+"."+string(format), values)
+	request, err := its.newRequest(ctx, http.MethodGet, urlString, nil, format)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := its.do(request, EndpointProductTypes, "GetAllProductTypes")
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		defer response.Body.Close()
+		return nil, NewUnexpectedStatusCodeError(response)
+	}
+
+	return response, nil
+}
+
+func (its *ITScopeCommunicator) GetProductAccessoriesFromList(ctx context.Context, products []string) ([]Product, error) {
+	return its.GetProductsByIDs(ctx, products)
+}
+
+// GetProductsByIDs fetches the products with the given IDs in batches of 50.
+func (its *ITScopeCommunicator) GetProductsByIDs(ctx context.Context, ids []string) ([]Product, error) {
+	ctx, done, err := its.beginOperation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsByIDs: %w", err)
+	}
+	defer done()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	productList := make([]Product, 0)
+
+	queryStrings := its.createQueryStrings("id", ids, 50)
+
+	for _, query := range queryStrings {
+		query := query
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		product, err := its.GetProductsFromQuery(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		productList = append(productList, product.Product...)
+
+	}
+
+	return productList, nil
+}
+
+// GetProductsFromQuery searches with a query of ";"-separated key=value
+// filters. It is parsed with ParseFilters and run like GetProductsFromFilters,
+// keeping the filters in the order given.
+func (its *ITScopeCommunicator) GetProductsFromQuery(ctx context.Context, query string, opts ...SearchOption) (*ProductsContainer, error) {
+	return its.searchFilters(ctx, ParseFilters(query), filterOrder(query), opts)
+}
+
+// SearchProducts runs a full-text search for text, sorted by relevance unless
+// SearchSort is given. Use SearchPage to page through the results.
+func (its *ITScopeCommunicator) SearchProducts(ctx context.Context, text string, opts ...SearchOption) (*ProductsContainer, error) {
+	text = strings.TrimSpace(strings.NewReplacer(";", " ", "=", " ").Replace(text))
+	if text == "" {
+		return nil, errors.New("SearchProducts: no search text")
+	}
+
+	opts = append([]SearchOption{SearchSort(SortRelevance)}, opts...)
+	return its.GetProductsFromFilters(ctx, url.Values{"search": {text}}, opts...)
+}
+
+// GetProductsFromFilters searches with the ITScope filters in filters, e.g.
+// url.Values{"manufacturer": {"HP"}, "producttype": {"ABC", "DEF"}}. Several
+// values of a key are ORed, different keys must all match.
+func (its *ITScopeCommunicator) GetProductsFromFilters(ctx context.Context, filters url.Values, opts ...SearchOption) (*ProductsContainer, error) {
+	return its.searchFilters(ctx, filters, nil, opts)
+}
+
+func (its *ITScopeCommunicator) searchFilters(ctx context.Context, filters url.Values, order []filterKey, opts []SearchOption) (*ProductsContainer, error) {
+	query, err := serializeFilters(filters, order)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsFromFilters: %w", err)
+	}
+	return its.runSearch(ctx, query, opts)
+}
+
+// runSearch runs the search for the serialized filters in query.
+func (its *ITScopeCommunicator) runSearch(ctx context.Context, query string, opts []SearchOption) (*ProductsContainer, error) {
+	params := newSearchParams(opts)
+	format := its.format
+	query = its.restrictToDistributors(query)
+	if its.queryRewriter != nil {
+		query = its.queryRewriter(query)
+	}
+	values := url.Values{}
+	values.Set("realtime", strconv.FormatBool(params.realtime))
+	values.Set("plzproducts", "false")
+	values.Set("page", strconv.Itoa(params.page))
+	values.Set("item", "0")
+	values.Set("sort", string(params.sort))
+	if len(params.fields) > 0 {
+		values.Set("fields", strings.Join(params.fields, ","))
+	}
+	urlString := its.buildURL("products/search/"+url.QueryEscape(query)+"/"+string(params.representation)+"."+string(format), values)
+	request, err := its.newRequest(ctx, http.MethodGet, urlString, nil, format)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsFromQuery1: %w", err)
+	}
+
+	endpoint := EndpointSearch
+	if params.realtime {
+		endpoint = EndpointRealtime
+	}
+
+	signature := requestSignature(request)
+	cacheable := !params.realtime
+	if cacheable {
+		if products, ok := its.cachedResponse(signature, false); ok {
+			return products, nil
+		}
+	}
+
+	products, err := its.sharedFetchProducts(request, signature, endpoint, format)
+	if err != nil {
+		if !cacheable || !servesStale(err) {
+			return nil, err
+		}
+		if stale, ok := its.cachedResponse(signature, true); ok {
+			return stale, nil
+		}
+		return nil, err
+	}
+	if cacheable {
+		its.storeResponse(signature, products)
+	}
+
+	return products, nil
+}
+
+// sharedFetchProducts fetches the products of request, sharing the call with
+// concurrent identical requests when singleflight is enabled.
+func (its *ITScopeCommunicator) sharedFetchProducts(request *http.Request, signature string, endpoint Endpoint, format Format) (*ProductsContainer, error) {
+	if its.inflight == nil {
+		return its.fetchProducts(request, endpoint, format)
+	}
+	products, err := its.shared(request.Context(), signature, func(ctx context.Context) (any, error) {
+		return its.fetchProducts(request.WithContext(ctx), endpoint, format)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return products.(*ProductsContainer), nil
+}
+
+// shared runs fetch once for concurrent calls with the same key. fetch is
+// detached from the cancellation of ctx, so a caller giving up does not fail
+// the others, while each caller still returns once its own ctx is done.
+func (its *ITScopeCommunicator) shared(ctx context.Context, key string, fetch func(ctx context.Context) (any, error)) (any, error) {
+	results := its.inflight.DoChan(key, func() (any, error) {
+		return fetch(context.WithoutCancel(ctx))
+	})
+	select {
+	case result := <-results:
+		return result.Val, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (its *ITScopeCommunicator) fetchProducts(request *http.Request, endpoint Endpoint, format Format) (*ProductsContainer, error) {
+	var products *ProductsContainer
+	err := its.withDecodeRetries(request.Context(), "GetProductsFromQuery", func() (err error) {
+		products, err = its.fetchProductsOnce(request, endpoint, format)
+		return err
+	})
+	return products, err
+}
+
+func (its *ITScopeCommunicator) fetchProductsOnce(request *http.Request, endpoint Endpoint, format Format) (*ProductsContainer, error) {
+	response, err := its.do(request, endpoint, "GetProductsFromQuery")
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsFromQuery: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound && its.notFoundAsError(request.Context()) {
+		return nil, NewUnexpectedStatusCodeError(response)
+	} else if response.StatusCode == http.StatusNotFound {
+		return &ProductsContainer{}, nil
+	} else if response.StatusCode != http.StatusOK {
+		return nil, NewUnexpectedStatusCodeError(response)
+	}
+	var products ProductsContainer
+	err = its.decode(response, format, &products)
+	if err != nil {
+		return nil, err
+	}
+	its.filterDistributorOffers(products.Product)
+
+	return &products, nil
 }
 
-func (its *ITScopeCommunicator) authenticateRequest(request *http.Request) error {
-	if its.username == "" || its.password == "" {
-		return fmt.Errorf("no username or password set")
+// restrictToDistributors adds the distributors configured with
+// WithDistributors to query.
+func (its *ITScopeCommunicator) restrictToDistributors(query string) string {
+	if len(its.distributors) == 0 {
+		return query
 	}
 
-	request.SetBasicAuth(its.username, its.password)
-	request.Header.Add("Accept", "application/json")
-	request.Header.Add("UserAgent", its.userAgent)
-	request.Header.Add("Accept-Language", string(its.language))
+	ids := make([]string, 0, len(its.distributors))
+	for id := range its.distributors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
 
-	return nil
+	restriction := NewProductQuery().AnyOf("supplierid", ids...).String()
+	if query == "" {
+		return restriction
+	}
+	return query + ";" + restriction
 }
 
-func (its *ITScopeCommunicator) GetProductData(ctx context.Context, productSKU string) (*Product, error) {
-	productContainer, err := its.GetProductsFromQuery(ctx, "distpid="+productSKU)
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve product data: %w", err)
+// filterDistributorOffers drops the supplier items of distributors not
+// configured with WithDistributors. Price and stock summaries taken from a
+// dropped distributor are recomputed from the remaining items.
+func (its *ITScopeCommunicator) filterDistributorOffers(products []Product) {
+	if len(its.distributors) == 0 {
+		return
 	}
 
-	if len(productContainer.Product) > 0 {
-		return &productContainer.Product[0], nil
-	} else {
-		return nil, nil
+	for i := range products {
+		items := products[i].SupplierItems[:0]
+		var aggregatedStock int64
+		for _, item := range products[i].SupplierItems {
+			if _, ok := its.distributors[item.SupplierID]; ok {
+				items = append(items, item)
+				aggregatedStock += max(parseInt(item.Stock), 0)
+			}
+		}
+		products[i].SupplierItems = items
+		products[i].AggregatedStock = strconv.FormatInt(aggregatedStock, 10)
+		products[i].AggregatedSupplierItems = strconv.Itoa(len(items))
+		if _, ok := its.distributors[products[i].PriceSupplierID]; !ok {
+			products[i].setPriceSource(cheapestItem(items))
+		}
 	}
 }
 
-func (its *ITScopeCommunicator) GetAllProductTypes(ctx context.Context) ([]ProductType, error) {
-	u := url.URL{
-		Host:   "api.itscope.com",
-		Scheme: "https",
-		Path:   "2.0/products/producttypes/producttype.json",
+// cheapestItem returns the cheapest priced item in stock, or the cheapest
+// priced item if none is in stock, or nil if no item has a price.
+func cheapestItem(items []SupplierItem) *SupplierItem {
+	var cheapest *SupplierItem
+	for i := range items {
+		item := &items[i]
+		price := parseFloat(item.Price)
+		if price <= 0 {
+			continue
+		}
+		if cheapest == nil {
+			cheapest = item
+			continue
+		}
+		inStock, cheapestInStock := parseInt(item.Stock) > 0, parseInt(cheapest.Stock) > 0
+		if (inStock && !cheapestInStock) || (inStock == cheapestInStock && price < parseFloat(cheapest.Price)) {
+			cheapest = item
+		}
 	}
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+	return cheapest
+}
+
+// setPriceSource copies the price and stock summary of p from item, or
+// clears it if item is nil.
+func (p *Product) setPriceSource(item *SupplierItem) {
+	if item == nil {
+		item = &SupplierItem{}
 	}
-	err = its.authenticateRequest(request)
+	p.Price = item.Price
+	p.PriceCalc = item.PriceCalc
+	p.PriceCalcVat = item.PriceCalcVat
+	p.PriceLastUpdate = item.PriceLastUpdate
+	p.PriceSupplierID = item.SupplierID
+	p.PriceSupplierName = item.SupplierName
+	p.PriceSupplierItemID = item.ID
+	p.PriceSupplierSKU = item.SupplierSKU
+	p.Stock = item.Stock
+	p.StockStatus = item.StockStatus
+	p.StockStatusText = item.StockStatusText
+	p.StockAvailabilityDate = item.StockAvailabilityDate
+}
+
+// GetProductsByMPN looks up products by manufacturer part number. The result
+// is keyed by the requested MPN; MPNs without a match are absent. If an MPN
+// matches several products, the first one in ITScope's result order is used.
+func (its *ITScopeCommunicator) GetProductsByMPN(ctx context.Context, mpns []string) (map[string]*Product, error) {
+	ctx, done, err := its.beginOperation(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+		return nil, fmt.Errorf("GetProductsByMPN: %w", err)
 	}
+	defer done()
 
-	retries := 3
-	var response *http.Response
-	for retries > 0 {
-		if err = its.limiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("limiter timeout: %w", err)
+	result := make(map[string]*Product, len(mpns))
+	if len(mpns) == 0 {
+		return result, nil
+	}
+
+	matches := make(map[string]*Product)
+	for _, query := range its.createQueryStrings("mpn", mpns, 50) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		response, err = its.client.Do(request)
-		if err != nil || (response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound) {
-			logrus.Errorln("Error during GetAllProductTypes, retrying...")
-			time.Sleep(4 * time.Second)
-			retries -= 1
-		} else {
-			break
+		container, err := its.GetProductsFromQuery(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("GetProductsByMPN: %w", err)
+		}
+
+		for i := range container.Product {
+			key := normalizeMPN(container.Product[i].ManufacturerSKU)
+			if _, ok := matches[key]; !ok {
+				matches[key] = &container.Product[i]
+			}
+		}
+	}
+
+	for _, mpn := range mpns {
+		if product, ok := matches[normalizeMPN(mpn)]; ok {
+			result[mpn] = product
 		}
 	}
+
+	return result, nil
+}
+
+// ValidateSKUs reports for each distributor SKU whether a product offered
+// under it exists. Only Puid and supplier items are requested.
+func (its *ITScopeCommunicator) ValidateSKUs(ctx context.Context, skus []string) (map[string]bool, error) {
+	ctx, done, err := its.beginOperation(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+		return nil, fmt.Errorf("ValidateSKUs: %w", err)
 	}
-	defer response.Body.Close()
+	defer done()
 
-	if response.StatusCode == http.StatusNotFound {
-		return []ProductType{}, nil
-	} else if response.StatusCode != http.StatusOK {
-		return nil, NewUnexpectedStatusCodeError(response)
+	found := make(map[string]struct{})
+	for _, query := range its.createQueryStrings("distpid", skus, 50) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		container, err := its.GetProductsFromQuery(ctx, query, SearchFields("puid", "supplierItems"))
+		if err != nil {
+			return nil, fmt.Errorf("ValidateSKUs: %w", err)
+		}
+
+		for _, product := range container.Product {
+			for _, item := range product.SupplierItems {
+				found[normalizeMPN(item.SupplierSKU)] = struct{}{}
+			}
+		}
 	}
-	var productTypes ProductTypesContainer
-	err = json.NewDecoder(response.Body).Decode(&productTypes)
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve product types: %w", err)
+
+	result := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		_, ok := found[normalizeMPN(sku)]
+		result[sku] = ok
 	}
 
-	return productTypes.ProductTypes, nil
+	return result, nil
 }
 
-func (its *ITScopeCommunicator) GetProductAccessoriesFromList(ctx context.Context, products []string) ([]Product, error) {
-	if len(products) == 0 {
-		return nil, nil
+func normalizeMPN(mpn string) string {
+	return strings.ToUpper(strings.TrimSpace(mpn))
+}
+
+// GetDeals returns the products currently on special offer. Use SearchPage to
+// page through them and Product.DealActive to hide expired deals.
+func (its *ITScopeCommunicator) GetDeals(ctx context.Context, opts ...SearchOption) (*ProductsContainer, error) {
+	container, err := its.GetProductsFromQuery(ctx, "specialoffer=true", opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeals: %w", err)
 	}
 
-	productList := make([]Product, 0)
+	return container, nil
+}
 
-	queryStrings := its.createQueryStrings(products, 50)
+// CountProducts returns the total number of products matching query.
+func (its *ITScopeCommunicator) CountProducts(ctx context.Context, query string) (int, error) {
+	container, err := its.GetProductsFromQuery(ctx, query, SearchFields("puid"))
+	if err != nil {
+		return 0, fmt.Errorf("CountProducts: %w", err)
+	}
 
-	for _, query := range queryStrings {
-		query := query
+	return max(container.TotalProductCount, len(container.Product)), nil
+}
 
-		product, err := its.GetProductsFromQuery(ctx, query)
+// GetAllProducts pages through all products matching query. With maxResults
+// above 0 it stops as soon as that many products were fetched, so no page
+// beyond the one reaching the limit is requested. If ITScope truncated the
+// result, the products are returned with ErrResultsTruncated.
+func (its *ITScopeCommunicator) GetAllProducts(ctx context.Context, query string, maxResults int, opts ...SearchOption) ([]Product, error) {
+	products := make([]Product, 0)
+	for page := 1; ; page++ {
+		container, err := its.GetProductsFromQuery(ctx, query, append(opts[:len(opts):len(opts)], SearchPage(page))...)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("GetAllProducts: %w", err)
 		}
+		products = append(products, container.Product...)
 
-		productList = append(productList, product.Product...)
-
+		if maxResults > 0 && len(products) >= maxResults {
+			return products[:maxResults], nil
+		}
+		if len(container.Product) == 0 || len(products) >= container.TotalProductCount {
+			if container.Truncated {
+				return products, fmt.Errorf("GetAllProducts: %w", ErrResultsTruncated)
+			}
+			return products, nil
+		}
 	}
-
-	return productList, nil
 }
 
-func (its *ITScopeCommunicator) GetProductsFromQuery(ctx context.Context, query string) (*ProductsContainer, error) {
-	urlString := "https://api.itscope.com/2.0/products/search/" + url.QueryEscape(query) + "/standard.json?realtime=false&plzproducts=false&page=1&item=0&sort=DEFAULT"
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, urlString, nil)
+// GetProductsChangedBetween returns the products modified within [from, to).
+// ITScope only filters by a lower bound, so the upper bound is applied to
+// Product.ModifiedAt; products without a modification date are skipped.
+func (its *ITScopeCommunicator) GetProductsChangedBetween(ctx context.Context, from time.Time, to time.Time) ([]Product, error) {
+	products, err := its.GetAllProducts(ctx, "modifiedsince="+from.UTC().Format(time.RFC3339), 0)
+	if err != nil && !errors.Is(err, ErrResultsTruncated) {
+		return nil, fmt.Errorf("GetProductsChangedBetween: %w", err)
+	}
+
+	changed := make([]Product, 0, len(products))
+	for _, product := range products {
+		modified, ok := product.ModifiedAt()
+		if ok && !modified.Before(from) && modified.Before(to) {
+			changed = append(changed, product)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("GetProductsFromQuery1: %w", err)
+		return changed, fmt.Errorf("GetProductsChangedBetween: %w", err)
 	}
-	err = its.authenticateRequest(request)
+
+	return changed, nil
+}
+
+// GetProductIDs returns the Puids of all products matching query. ITScope has
+// no ID-only representation, so the pages are requested with only the puid
+// field. Like GetAllProducts it reports truncation with ErrResultsTruncated.
+func (its *ITScopeCommunicator) GetProductIDs(ctx context.Context, query string) ([]string, error) {
+	ctx, done, err := its.beginOperation(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("GetProductsFromQuery2: %w", err)
+		return nil, fmt.Errorf("GetProductIDs: %w", err)
 	}
+	defer done()
 
-	retries := 3
-	var response *http.Response
-	for retries > 0 {
-		if err = its.limiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("limiter timeout: %w", err)
+	ids := make([]string, 0)
+	for page := 1; ; page++ {
+		container, err := its.GetProductsFromQuery(ctx, query, SearchFields("puid"), SearchPage(page))
+		if err != nil {
+			return nil, fmt.Errorf("GetProductIDs: %w", err)
+		}
+		for _, product := range container.Product {
+			ids = append(ids, product.Puid)
 		}
 
-		response, err = its.client.Do(request)
-		if err != nil || (response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound) {
-			logrus.Errorln("Error during GetProductsFromQuery, retrying...")
-			retries -= 1
-			time.Sleep(4 * time.Second)
-		} else {
-			break
+		if len(container.Product) == 0 || len(ids) >= container.TotalProductCount {
+			if container.Truncated {
+				return ids, fmt.Errorf("GetProductIDs: %w", ErrResultsTruncated)
+			}
+			return ids, nil
 		}
 	}
+}
+
+// GetAllProductsConcurrent fetches every result page of query with up to
+// concurrency parallel requests and returns the products in page order. All
+// requests still pass the communicator's rate limiter. Like GetAllProducts it
+// reports truncation with ErrResultsTruncated.
+func (its *ITScopeCommunicator) GetAllProductsConcurrent(ctx context.Context, query string, concurrency int) ([]Product, error) {
+	ctx, done, err := its.beginOperation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllProductsConcurrent: %w", err)
+	}
+	defer done()
+
+	first, err := its.GetProductsFromQuery(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("GetProductsFromQuery: %w", err)
+		return nil, fmt.Errorf("GetAllProductsConcurrent: %w", err)
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusNotFound {
-		return &ProductsContainer{}, nil
-	} else if response.StatusCode != http.StatusOK {
-		return nil, NewUnexpectedStatusCodeError(response)
+	pageSize := len(first.Product)
+	if pageSize == 0 || first.TotalProductCount <= pageSize {
+		if first.Truncated {
+			return first.Product, fmt.Errorf("GetAllProductsConcurrent: %w", ErrResultsTruncated)
+		}
+		return first.Product, nil
 	}
-	var products ProductsContainer
-	err = json.NewDecoder(response.Body).Decode(&products)
-	if err != nil {
+	pages := (first.TotalProductCount + pageSize - 1) / pageSize
+
+	results := make([][]Product, pages)
+	results[0] = first.Product
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var workerErr error
+	var truncated atomic.Bool
+	truncated.Store(first.Truncated)
+	pageNumbers := make(chan int)
+	for i := 0; i < max(concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pageNumbers {
+				container, err := its.GetProductsFromQuery(workerCtx, query, SearchPage(page))
+				if err != nil {
+					once.Do(func() {
+						workerErr = err
+						cancel()
+					})
+					continue
+				}
+				results[page-1] = container.Product
+				if container.Truncated {
+					truncated.Store(true)
+				}
+			}
+		}()
+	}
+
+feed:
+	for page := 2; page <= pages; page++ {
+		select {
+		case pageNumbers <- page:
+		case <-workerCtx.Done():
+			break feed
+		}
+	}
+	close(pageNumbers)
+	wg.Wait()
+
+	if workerErr != nil {
+		return nil, fmt.Errorf("GetAllProductsConcurrent: %w", workerErr)
+	}
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	return &products, nil
+	products := make([]Product, 0, first.TotalProductCount)
+	for _, page := range results {
+		products = append(products, page...)
+	}
+	if truncated.Load() {
+		return products, fmt.Errorf("GetAllProductsConcurrent: %w", ErrResultsTruncated)
+	}
+
+	return products, nil
 }
 
-func (its *ITScopeCommunicator) createQueryStrings(productIDs []string, length int) []string {
+// createQueryStrings ORs values under key in queries of at most length values
+// each, split further where a query would exceed maxQueryLength. Empty and
+// whitespace-only values are skipped.
+func (its *ITScopeCommunicator) createQueryStrings(key string, values []string, length int) []string {
 	var requestQuerys = make([]string, 0)
-	var pages = int(len(productIDs) / length)
+	nonEmpty := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	values = nonEmpty
+	var pages = int(len(values) / length)
 
-	if len(productIDs)%length > 0 {
+	if len(values)%length > 0 {
 		pages = pages + 1
 	}
 
@@ -199,12 +1261,11 @@ func (its *ITScopeCommunicator) createQueryStrings(productIDs []string, length i
 		end := (i) * length
 		var slice []string
 		if i == pages {
-			slice = productIDs[start:]
+			slice = values[start:]
 		} else {
-			slice = productIDs[start:end]
+			slice = values[start:end]
 		}
-		var query = "id=" + strings.Join(slice, ";id=")
-		requestQuerys = append(requestQuerys, query)
+		requestQuerys = append(requestQuerys, ChunkByURLLength(key+"=", slice, ";", maxQueryLength)...)
 	}
 
 	return requestQuerys
@@ -228,9 +1289,71 @@ func (its *ITScopeCommunicator) GetProductImages(product *Product) []string {
 		imageUrls = append(imageUrls, product.Image5)
 	}
 
+	if its.normalizeImages {
+		normalized := make([]string, 0, len(imageUrls))
+		for _, imageUrl := range imageUrls {
+			if imageUrl, ok := NormalizeImageURL(imageUrl); ok {
+				normalized = append(normalized, imageUrl)
+			}
+		}
+		return normalized
+	}
+
 	return imageUrls
 }
 
+// NormalizeImageURL upgrades http:// and protocol-relative image URLs to
+// https://. It reports false for URLs that are empty, relative or use another
+// scheme.
+func NormalizeImageURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "//") {
+		raw = "https:" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		u.Scheme = "https"
+	default:
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+// GetProductImagesDetailed returns the thumbnail, standard and high
+// resolution images of product with their role and, where ITScope provides
+// them, their dimensions.
+func (its *ITScopeCommunicator) GetProductImagesDetailed(product *Product) []ProductImage {
+	images := make([]ProductImage, 0)
+	add := func(url string, role ImageRole, width string, height string) {
+		if url != "" && its.normalizeImages {
+			url, _ = NormalizeImageURL(url)
+		}
+		if url != "" {
+			images = append(images, ProductImage{URL: url, Role: role, Width: int(parseInt(width)), Height: int(parseInt(height))})
+		}
+	}
+
+	add(product.ImageThumb, ImageRoleThumbnail, product.ImageThumbWidth, product.ImageThumbHeight)
+	add(product.Image1, ImageRoleStandard, product.ImageWidth1, product.ImageHeight1)
+	add(product.Image2, ImageRoleStandard, product.ImageWidth2, product.ImageHeight2)
+	add(product.Image3, ImageRoleStandard, product.ImageWidth3, product.ImageHeight3)
+	add(product.Image4, ImageRoleStandard, product.ImageWidth4, product.ImageHeight4)
+	add(product.Image5, ImageRoleStandard, product.ImageWidth5, product.ImageHeight5)
+	add(product.ImageHighRes1, ImageRoleHighRes, "", "")
+	add(product.ImageHighRes2, ImageRoleHighRes, "", "")
+	add(product.ImageHighRes3, ImageRoleHighRes, "", "")
+	add(product.ImageHighRes4, ImageRoleHighRes, "", "")
+	add(product.ImageHighRes5, ImageRoleHighRes, "", "")
+
+	return images
+}
+
 func (its *ITScopeCommunicator) FilterProductTypesByGroupId(groupId string, productTypes []ProductType) []ProductType {
 	filteredTypes := make([]ProductType, 0)
 
@@ -255,41 +1378,195 @@ func (its *ITScopeCommunicator) FilterProductTypesById(id string, productTypes [
 	return filteredTypes
 }
 
+// FilterProductsByTypeList returns the products whose type is in typeList,
+// deduplicated by Puid and in the order they first appear in products.
 func (its *ITScopeCommunicator) FilterProductsByTypeList(products []Product, typeList []ProductType) []Product {
-	filteredProducts := make(map[string]Product)
-
+	types := make(map[string]struct{}, len(typeList))
 	for _, productType := range typeList {
-		for _, product := range products {
-			if product.ProductTypeID == productType.ID && product.ProductTypeID != "" && productType.ProductTypeGroup.ID != "" {
-				filteredProducts[product.Puid] = product
-			}
+		if productType.ID != "" && productType.ProductTypeGroup.ID != "" {
+			types[productType.ID] = struct{}{}
+		}
+	}
+
+	seen := make(map[string]struct{})
+	filteredProducts := make([]Product, 0)
+	for _, product := range products {
+		if _, ok := types[product.ProductTypeID]; !ok {
+			continue
+		}
+		if _, ok := seen[product.Puid]; ok {
+			continue
+		}
+		seen[product.Puid] = struct{}{}
+		filteredProducts = append(filteredProducts, product)
+	}
+
+	return filteredProducts
+}
+
+// DistinctGroupIDs returns the sorted, unique product-type group IDs of the
+// given products, resolved through productTypes.
+func (its *ITScopeCommunicator) DistinctGroupIDs(products []Product, productTypes []ProductType) []string {
+	groupsByType := make(map[string]string, len(productTypes))
+	for _, productType := range productTypes {
+		if productType.ID != "" && productType.ProductTypeGroup.ID != "" {
+			groupsByType[productType.ID] = productType.ProductTypeGroup.ID
+		}
+	}
+
+	seen := make(map[string]struct{})
+	groupIDs := make([]string, 0)
+	for _, product := range products {
+		groupID, ok := groupsByType[product.ProductTypeID]
+		if !ok {
+			continue
 		}
+		if _, ok := seen[groupID]; ok {
+			continue
+		}
+		seen[groupID] = struct{}{}
+		groupIDs = append(groupIDs, groupID)
 	}
+	sort.Strings(groupIDs)
 
-	filteredProductsArray := make([]Product, len(filteredProducts))
-	iterator := 0
+	return groupIDs
+}
 
-	for _, item := range filteredProducts {
-		filteredProductsArray[iterator] = item
-		iterator++
+// DistinctManufacturers returns the sorted, unique manufacturer names of the
+// given products. Products without a manufacturer name are skipped.
+func (its *ITScopeCommunicator) DistinctManufacturers(products []Product) []string {
+	seen := make(map[string]struct{})
+	manufacturers := make([]string, 0)
+	for _, product := range products {
+		manufacturer := strings.TrimSpace(product.ManufacturerName)
+		if manufacturer == "" {
+			continue
+		}
+		if _, ok := seen[manufacturer]; ok {
+			continue
+		}
+		seen[manufacturer] = struct{}{}
+		manufacturers = append(manufacturers, manufacturer)
 	}
+	sort.Strings(manufacturers)
 
-	return filteredProductsArray
+	return manufacturers
 }
 
 func (its *ITScopeCommunicator) GetServiceTypeAccessoriesOfProduct(ctx context.Context, product *Product) ([]Product, error) {
-	productTypes, err := its.GetAllProductTypes(ctx)
+	return its.GetAccessoriesInGroup(ctx, product, ProductTypeGroupService)
+}
+
+// GetAccessoriesInGroup returns the accessories of product whose product type
+// belongs to the product-type group groupID.
+func (its *ITScopeCommunicator) GetAccessoriesInGroup(ctx context.Context, product *Product, groupID string) ([]Product, error) {
+	accessories, err := its.GetProductAccessories(ctx, product)
 	if err != nil {
-		return nil, fmt.Errorf("GetServiceTypeAccessoriesOfProduct: %w", err)
+		return nil, fmt.Errorf("GetAccessoriesInGroup: %w", err)
 	}
 
-	accessories, err := its.GetProductAccessories(ctx, product)
+	groupTypes, ok, err := its.groupTypes(ctx, "GetAccessoriesInGroup", groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return accessories, nil
+	}
+
+	return its.FilterProductsByTypeList(accessories, groupTypes), nil
+}
+
+// groupTypes returns the product types of the group groupID for method to
+// filter by. ok is false if they could not be fetched and best-effort
+// filtering lets method return its products unfiltered.
+func (its *ITScopeCommunicator) groupTypes(ctx context.Context, method string, groupID string) ([]ProductType, bool, error) {
+	productTypes, err := its.cachedProductTypes(ctx)
+	if err == nil {
+		return its.FilterProductTypesByGroupId(groupID, productTypes), true, nil
+	}
+	if !its.bestEffortTypes {
+		return nil, false, fmt.Errorf("%s: %w", method, err)
+	}
+
+	err = fmt.Errorf("%s: %w: %w", method, ErrDegraded, err)
+	its.logger.WithField("operation", method).Warnln(err.Error())
+	if its.onDegraded != nil {
+		its.onDegraded(err)
+	}
+	return nil, false, nil
+}
+
+// GetServiceAccessoriesForProducts is the batch version of
+// GetServiceTypeAccessoriesOfProduct: it fetches the product types once and
+// all accessories in deduplicated chunks, and returns the service
+// accessories per parent Puid.
+func (its *ITScopeCommunicator) GetServiceAccessoriesForProducts(ctx context.Context, products []Product) (map[string][]Product, error) {
+	accessories, err := its.GetAccessoriesForProducts(ctx, products)
+	if err != nil {
+		return nil, fmt.Errorf("GetServiceAccessoriesForProducts: %w", err)
+	}
+
+	serviceTypes, ok, err := its.groupTypes(ctx, "GetServiceAccessoriesForProducts", ProductTypeGroupService)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return accessories, nil
+	}
+
+	for puid, parentAccessories := range accessories {
+		accessories[puid] = its.FilterProductsByTypeList(parentAccessories, serviceTypes)
+	}
+
+	return accessories, nil
+}
+
+// SearchInGroup runs query and keeps only the products whose type belongs to
+// the product-type group groupID.
+func (its *ITScopeCommunicator) SearchInGroup(ctx context.Context, query string, groupID string) ([]Product, error) {
+	container, err := its.GetProductsFromQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("SearchInGroup: %w", err)
+	}
+
+	groupTypes, ok, err := its.groupTypes(ctx, "SearchInGroup", groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return container.Product, nil
+	}
+
+	return its.FilterProductsByTypeList(container.Product, groupTypes), nil
+}
+
+// GetProductsWithTypes runs query and joins each product with its product
+// type. Products of unknown types are kept with a nil Type.
+func (its *ITScopeCommunicator) GetProductsWithTypes(ctx context.Context, query string) ([]ProductWithType, error) {
+	container, err := its.GetProductsFromQuery(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("GetServiceTypeAccessoriesOfProduct: %w", err)
+		return nil, fmt.Errorf("GetProductsWithTypes: %w", err)
+	}
+
+	productTypes, err := its.cachedProductTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsWithTypes: %w", err)
+	}
+
+	typesByID := make(map[string]ProductType, len(productTypes))
+	for _, productType := range productTypes {
+		typesByID[productType.ID] = productType
+	}
+
+	products := make([]ProductWithType, len(container.Product))
+	for i, product := range container.Product {
+		products[i] = ProductWithType{Product: product}
+		if productType, ok := typesByID[product.ProductTypeID]; ok {
+			products[i].Type = &productType
+		}
 	}
 
-	serviceTypes := its.FilterProductTypesByGroupId("SSP", productTypes)
-	return its.FilterProductsByTypeList(accessories, serviceTypes), nil
+	return products, nil
 }
 
 func (its *ITScopeCommunicator) GetProductAccessories(ctx context.Context, product *Product) ([]Product, error) {
@@ -305,3 +1582,84 @@ func (its *ITScopeCommunicator) GetProductAccessories(ctx context.Context, produ
 
 	return accessories, nil
 }
+
+// GetAccessoriesForProducts fetches the accessories of all products at once,
+// requesting every accessory only once, and maps them to the Puid of each
+// parent referencing them. Products without accessories map to an empty slice.
+func (its *ITScopeCommunicator) GetAccessoriesForProducts(ctx context.Context, products []Product) (map[string][]Product, error) {
+	seen := make(map[string]struct{})
+	accessoryIds := make([]string, 0)
+	for _, product := range products {
+		for _, accessory := range product.Accessories {
+			if _, ok := seen[accessory.ReferencedProductID]; ok {
+				continue
+			}
+			seen[accessory.ReferencedProductID] = struct{}{}
+			accessoryIds = append(accessoryIds, accessory.ReferencedProductID)
+		}
+	}
+
+	accessories, err := its.GetProductAccessoriesFromList(ctx, accessoryIds)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccessoriesForProducts: %w", err)
+	}
+
+	byID := productsByID(accessories)
+	result := make(map[string][]Product, len(products))
+	for _, parent := range products {
+		attachAccessories(&parent, byID)
+		result[parent.Puid] = parent.ResolvedAccessories
+	}
+
+	return result, nil
+}
+
+// AttachAccessories sets parent.ResolvedAccessories to the products of
+// accessories that parent references, in the order of parent.Accessories.
+func (its *ITScopeCommunicator) AttachAccessories(parent *Product, accessories []Product) {
+	attachAccessories(parent, productsByID(accessories))
+}
+
+func productsByID(products []Product) map[string]Product {
+	byID := make(map[string]Product, len(products))
+	for _, product := range products {
+		byID[product.Puid] = product
+	}
+	return byID
+}
+
+func attachAccessories(parent *Product, byID map[string]Product) {
+	seen := make(map[string]struct{})
+	parent.ResolvedAccessories = make([]Product, 0, len(parent.Accessories))
+	for _, reference := range parent.Accessories {
+		accessory, ok := byID[reference.ReferencedProductID]
+		if !ok {
+			continue
+		}
+		if _, ok := seen[accessory.Puid]; ok {
+			continue
+		}
+		seen[accessory.Puid] = struct{}{}
+		parent.ResolvedAccessories = append(parent.ResolvedAccessories, accessory)
+	}
+}
+
+// GetBundleComponents fetches the products that make up the bundle product.
+// Products that are not bundles have no components.
+func (its *ITScopeCommunicator) GetBundleComponents(ctx context.Context, product *Product) ([]Product, error) {
+	if len(product.BundleComponents) == 0 {
+		return []Product{}, nil
+	}
+
+	componentIds := make([]string, len(product.BundleComponents))
+	for i, v := range product.BundleComponents {
+		componentIds[i] = v.ReferencedProductID
+	}
+
+	components, err := its.GetProductsByIDs(ctx, componentIds)
+	if err != nil {
+		return nil, fmt.Errorf("GetBundleComponents: %w", err)
+	}
+
+	return components, nil
+}
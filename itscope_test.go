@@ -0,0 +1,170 @@
+package itscope
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetProductAccessoriesFromListStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int64
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		cancel()
+		return jsonResponse(request, http.StatusOK, `{"product":[{"puid":"1"}]}`), nil
+	}))
+
+	ids := make([]string, 150)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+
+	start := time.Now()
+	_, err := its.GetProductAccessoriesFromList(ctx, ids)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %s", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestCreateQueryStringsSkipsEmptyIDs(t *testing.T) {
+	its := &ITScopeCommunicator{}
+	for name, test := range map[string]struct {
+		values []string
+		want   []string
+	}{
+		"mixed":     {values: []string{"1", "", " ", "2", "\t", " 3 "}, want: []string{"id=1;id=2;id=3"}},
+		"all empty": {values: []string{"", " ", "\n"}, want: []string{}},
+		"nil":       {values: nil, want: []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := its.createQueryStrings("id", test.values, 50)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetProductsFromQuerySendsQueryVerbatim(t *testing.T) {
+	queries := []string{
+		"distpid=abc;manufacturer=HP;id=",
+		"supplier=12;search=toner",
+	}
+	for _, query := range queries {
+		var sent string
+		its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+			sent = request.URL.EscapedPath()
+			return jsonResponse(request, http.StatusOK, `{"product":[]}`), nil
+		}))
+
+		if _, err := its.GetProductsFromQuery(context.Background(), query); err != nil {
+			t.Fatal(err)
+		}
+		if want := "/products/search/" + url.QueryEscape(query) + "/"; !strings.Contains(sent, want) {
+			t.Errorf("GetProductsFromQuery(%q) requested %s, want it to contain %s", query, sent, want)
+		}
+	}
+}
+
+func TestSearchInGroupBestEffort(t *testing.T) {
+	transport := roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		if strings.Contains(request.URL.Path, "/producttypes/") {
+			return jsonResponse(request, http.StatusInternalServerError, `{}`), nil
+		}
+		return jsonResponse(request, http.StatusOK, `{"product":[{"puid":"1","productTypeId":"A"},{"puid":"2","productTypeId":"B"}]}`), nil
+	})
+
+	var degraded []error
+	its := newTestCommunicator(transport, WithNoRetry(), WithBestEffortTypeFiltering(func(err error) {
+		degraded = append(degraded, err)
+	}))
+	products, err := its.SearchInGroup(context.Background(), "manufacturer=HP", ProductTypeGroupService)
+	if err != nil {
+		t.Fatalf("got error %v, want the unfiltered products", err)
+	}
+	if len(products) != 2 {
+		t.Errorf("got %d products, want 2", len(products))
+	}
+	if len(degraded) != 1 || !errors.Is(degraded[0], ErrDegraded) {
+		t.Errorf("got degraded errors %v, want one matching ErrDegraded", degraded)
+	}
+
+	its = newTestCommunicator(transport, WithNoRetry())
+	if _, err := its.SearchInGroup(context.Background(), "manufacturer=HP", ProductTypeGroupService); err == nil || errors.Is(err, ErrDegraded) {
+		t.Errorf("got error %v without best effort, want the product-types error", err)
+	}
+}
+
+func TestWithDistributorsRecomputesPriceSummary(t *testing.T) {
+	its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		return jsonResponse(request, http.StatusOK, `{"product":[{
+			"puid": "1",
+			"price": "10,00", "priceSupplierId": "3", "priceSupplierName": "Excluded", "priceSupplierSKU": "EX-1", "priceSupplierItemId": "30",
+			"stock": "5", "aggregatedStock": "7", "aggregatedSupplierItems": "3",
+			"supplierItems": [
+				{"id": "30", "supplierId": "3", "supplierName": "Excluded", "supplierSKU": "EX-1", "price": "10,00", "stock": "5"},
+				{"id": "10", "supplierId": "1", "supplierName": "Allowed", "supplierSKU": "AL-1", "price": "20,00", "stock": "2"},
+				{"id": "20", "supplierId": "2", "supplierName": "Sold out", "supplierSKU": "SO-1", "price": "15,00", "stock": "0"}
+			]
+		}]}`), nil
+	}), WithDistributors("1", "2"))
+
+	container, err := its.GetProductsFromQuery(context.Background(), "puid=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := container.Product[0]
+
+	if len(product.SupplierItems) != 2 {
+		t.Errorf("got %d supplier items, want 2", len(product.SupplierItems))
+	}
+	got := []string{product.Price, product.PriceSupplierID, product.PriceSupplierName, product.PriceSupplierSKU, product.PriceSupplierItemID, product.Stock, product.AggregatedStock, product.AggregatedSupplierItems}
+	want := []string{"20,00", "1", "Allowed", "AL-1", "10", "2", "2", "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got price summary %v, want %v", got, want)
+	}
+}
+
+func TestDecodeWithoutRequest(t *testing.T) {
+	its := newTestCommunicator(nil)
+	for name, test := range map[string]struct {
+		contentType string
+		body        string
+		want        error
+	}{
+		"unexpected content type": {contentType: "text/html", body: "<html></html>", want: ErrUnexpectedContentType},
+		"markup":                  {body: "<html></html>", want: ErrUnexpectedContentType},
+		"invalid body":            {contentType: "application/json", body: `{"product":`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(test.body))}
+			if test.contentType != "" {
+				response.Header.Set("Content-Type", test.contentType)
+			}
+
+			var container ProductsContainer
+			err := its.decode(response, FormatJSON, &container)
+			var decodeErr *DecodeError
+			if err == nil || test.want != nil && !errors.Is(err, test.want) || test.want == nil && !errors.As(err, &decodeErr) {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
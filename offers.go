@@ -0,0 +1,132 @@
+package itscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetRealtimePrices queries all distributors of a product live and returns
+// their current offers. Realtime requests are slow and expensive in quota, so
+// they are limited separately (1 request per second by default, see
+// WithEndpointRateLimit with EndpointRealtime).
+func (its *ITScopeCommunicator) GetRealtimePrices(ctx context.Context, puid string) ([]DistributorOffer, error) {
+	container, err := its.GetProductsFromQuery(ctx, "puid="+puid, SearchRealtime(true))
+	if err != nil {
+		var statusErr UnexpectedStatusCodeError
+		if errors.Is(err, context.DeadlineExceeded) ||
+			(errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusGatewayTimeout || statusErr.StatusCode == http.StatusRequestTimeout)) {
+			return nil, fmt.Errorf("GetRealtimePrices: %w: %w", ErrRealtimeTimeout, err)
+		}
+		return nil, fmt.Errorf("GetRealtimePrices: %w", err)
+	}
+
+	offers := make([]DistributorOffer, 0)
+	for _, product := range container.Product {
+		for _, item := range product.SupplierItems {
+			offers = append(offers, newDistributorOffer(item))
+		}
+	}
+
+	return offers, nil
+}
+
+func newDistributorOffer(item SupplierItem) DistributorOffer {
+	return DistributorOffer{
+		SupplierID:      item.SupplierID,
+		SupplierName:    item.SupplierName,
+		SupplierSKU:     item.SupplierSKU,
+		Price:           parseFloat(item.Price),
+		CurrencyCode:    item.CurrencyCode,
+		Stock:           parseInt(item.Stock),
+		StockStatus:     item.StockStatus,
+		AvailableFrom:   item.StockAvailabilityDate,
+		PriceLastUpdate: item.PriceLastUpdate,
+		StockLastUpdate: item.LastStockUpdate,
+	}
+}
+
+// Offers returns the offers of all distributors listing the product.
+func (p *Product) Offers() []DistributorOffer {
+	offers := make([]DistributorOffer, 0, len(p.SupplierItems))
+	for _, item := range p.SupplierItems {
+		offers = append(offers, newDistributorOffer(item))
+	}
+	return offers
+}
+
+// Cheapest scores offers by their price, lower being better. Offers without
+// a price are excluded.
+func Cheapest(offer DistributorOffer) float64 {
+	if offer.Price <= 0 {
+		return math.Inf(-1)
+	}
+	return -offer.Price
+}
+
+// BestOffer returns the offer with the highest score, Cheapest if scorer is
+// nil. Only offers in stock are considered unless IncludeOutOfStock is given.
+// Offers scored -Inf are never chosen; ok is false if no offer remains.
+func (p *Product) BestOffer(scorer func(DistributorOffer) float64, opts ...OfferOption) (offer DistributorOffer, ok bool) {
+	if scorer == nil {
+		scorer = Cheapest
+	}
+
+	best := math.Inf(-1)
+	for _, candidate := range p.filteredOffers(newOfferParams(opts)) {
+		if score := scorer(candidate); score > best {
+			best, offer, ok = score, candidate, true
+		}
+	}
+
+	return offer, ok
+}
+
+// CheapestPrice returns the lowest price of the offers in stock, or of all
+// offers with IncludeOutOfStock. ok is false if there is no priced offer.
+func (p *Product) CheapestPrice(opts ...OfferOption) (price float64, ok bool) {
+	offer, ok := p.BestOffer(Cheapest, opts...)
+	return offer.Price, ok
+}
+
+// InStockOffers returns the offers of distributors with stock.
+func (p *Product) InStockOffers() []DistributorOffer {
+	return p.filteredOffers(newOfferParams(nil))
+}
+
+func (p *Product) filteredOffers(params offerParams) []DistributorOffer {
+	offers := p.Offers()
+	if params.includeOutOfStock {
+		return offers
+	}
+
+	inStock := make([]DistributorOffer, 0, len(offers))
+	for _, offer := range offers {
+		if offer.Stock > 0 {
+			inStock = append(inStock, offer)
+		}
+	}
+	return inStock
+}
+
+// parseFloat parses numbers as delivered by ITScope, accepting a decimal
+// comma. Invalid numbers yield 0.
+func parseFloat(value string) float64 {
+	number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func parseInt(value string) int64 {
+	number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return int64(parseFloat(value))
+	}
+	return number
+}
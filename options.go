@@ -0,0 +1,425 @@
+package itscope
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+)
+
+// Option configures an ITScopeCommunicator at construction time.
+type Option func(its *ITScopeCommunicator)
+
+// WithRateLimit replaces the shared rate limiter used by all endpoints
+// without a limiter of their own. The default is 6 requests per second; a
+// ratePerSec of math.Inf(1) disables the limit.
+func WithRateLimit(ratePerSec float64, burst int) Option {
+	return func(its *ITScopeCommunicator) {
+		its.limiter = rate.NewLimiter(rate.Limit(ratePerSec), burst)
+	}
+}
+
+// WithoutRateLimit lets all requests pass without delay, including those of
+// endpoints with their own limiter such as realtime searches. Use it if rate
+// control happens elsewhere, e.g. in a proxy. Endpoint limiters set by
+// later options still apply.
+func WithoutRateLimit() Option {
+	return func(its *ITScopeCommunicator) {
+		its.limiter = rate.NewLimiter(rate.Inf, 0)
+		its.limiters = make(map[Endpoint]*rate.Limiter)
+	}
+}
+
+// WithEndpointRateLimit gives endpoint its own rate limiter, so its requests
+// no longer compete with other endpoints for the shared limiter.
+func WithEndpointRateLimit(endpoint Endpoint, ratePerSec float64, burst int) Option {
+	return func(its *ITScopeCommunicator) {
+		its.limiters[endpoint] = rate.NewLimiter(rate.Limit(ratePerSec), burst)
+	}
+}
+
+// WithPriorityRateLimit gives requests made with ContextWithPriority a rate
+// limiter of their own, so interactive lookups are not delayed by batch jobs
+// waiting for the shared limiter.
+func WithPriorityRateLimit(ratePerSec float64, burst int) Option {
+	return func(its *ITScopeCommunicator) {
+		its.priority = rate.NewLimiter(rate.Limit(ratePerSec), burst)
+	}
+}
+
+// WithRetryBudget caps the number of retries across all calls of the
+// communicator with a token bucket. Once the budget is exhausted a failing
+// request returns ErrRetryBudgetExhausted instead of being retried.
+func WithRetryBudget(ratePerSec float64, burst int) Option {
+	return func(its *ITScopeCommunicator) {
+		its.retryBudget = rate.NewLimiter(rate.Limit(ratePerSec), burst)
+	}
+}
+
+// WithMaxRetries sets how often a failed request is retried after the first
+// attempt. Zero disables retries, so errors surface after a single attempt.
+func WithMaxRetries(retries int) Option {
+	return func(its *ITScopeCommunicator) {
+		its.maxRetries = max(retries, 0)
+	}
+}
+
+// WithNoRetry is shorthand for WithMaxRetries(0).
+func WithNoRetry() Option {
+	return WithMaxRetries(0)
+}
+
+// WithRetryableStatusCodes replaces the set of status codes that trigger a
+// retry, which by default is every status except 2xx and 404. Transport
+// errors are always retried. Codes that are not valid HTTP statuses are
+// logged and ignored.
+func WithRetryableStatusCodes(codes ...int) Option {
+	retryable := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		retryable[code] = struct{}{}
+	}
+
+	return func(its *ITScopeCommunicator) {
+		its.retryableStatus = retryable
+	}
+}
+
+// WithRetryOnDecodeError retries requests whose response body cannot be
+// decoded, e.g. when truncated by a connection reset, within the maximum
+// number of retries. By default decode errors are returned right away, as
+// retrying a malformed response rarely helps.
+func WithRetryOnDecodeError() Option {
+	return func(its *ITScopeCommunicator) {
+		its.retryDecode = true
+	}
+}
+
+// WithTransportTuning raises the idle connection limits of the default HTTP
+// client. Go keeps only two idle connections per host by default, which forces
+// new TLS handshakes under concurrent use against the single ITScope host.
+// Higher limits keep more sockets open while idle. Like the default
+// transport, the tuned one negotiates HTTP/2, which multiplexes concurrent
+// requests over a single connection.
+func WithTransportTuning(maxIdle int, maxIdlePerHost int) Option {
+	return func(its *ITScopeCommunicator) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = maxIdle
+		transport.MaxIdleConnsPerHost = maxIdlePerHost
+		its.client.Transport = transport
+	}
+}
+
+// WithSingleflight collapses identical concurrent searches into one HTTP
+// call whose result is shared by all waiting callers. Concurrent
+// GetAllProductTypes calls, including those of composite methods, are
+// collapsed the same way, so a cold WithProductTypeCache is filled by a
+// single download. Shared results must be treated as read-only. The call
+// keeps the values but not the cancellation of the first caller's context;
+// every caller stops waiting when its own context is done.
+func WithSingleflight() Option {
+	return func(its *ITScopeCommunicator) {
+		its.inflight = &singleflight.Group{}
+	}
+}
+
+// WithClock replaces the real clock used for retry delays and cache expiry.
+func WithClock(clock Clock) Option {
+	return func(its *ITScopeCommunicator) {
+		its.clock = clock
+	}
+}
+
+// WithLogger replaces the standard logrus logger. Failed attempts that are
+// retried are logged as warnings, only requests that fail after the last
+// retry as errors.
+func WithLogger(logger logrus.FieldLogger) Option {
+	return func(its *ITScopeCommunicator) {
+		its.logger = logger
+	}
+}
+
+// WithProductTypeCache keeps the product types used by composite methods such
+// as SearchInGroup for ttl per language. GetAllProductTypes always fetches.
+func WithProductTypeCache(ttl time.Duration) Option {
+	return func(its *ITScopeCommunicator) {
+		its.typeCache = &productTypesCache{
+			ttl:     ttl,
+			entries: make(map[Language]productTypesCacheEntry),
+		}
+	}
+}
+
+// WithBestEffortTypeFiltering lets composite methods that filter by product
+// type, such as GetServiceTypeAccessoriesOfProduct, degrade instead
+// of failing when the product types cannot be fetched: they return the
+// unfiltered products without an error. The failure is logged and passed to
+// onDegraded, if not nil, as an error matching ErrDegraded.
+func WithBestEffortTypeFiltering(onDegraded func(err error)) Option {
+	return func(its *ITScopeCommunicator) {
+		its.bestEffortTypes = true
+		its.onDegraded = onDegraded
+	}
+}
+
+// WithNotFoundAsError makes searches and product-type fetches return an
+// error matching ErrNotFound on 404 instead of an empty result. Use
+// ContextWithNotFoundAsError to choose per call.
+func WithNotFoundAsError() Option {
+	return func(its *ITScopeCommunicator) {
+		its.notFoundErrors = true
+	}
+}
+
+// WithMaxLimiterWait makes requests fail with ErrRateLimited instead of
+// waiting when the rate limiter would delay them longer than maxWait. By
+// default requests wait as long as needed.
+func WithMaxLimiterWait(maxWait time.Duration) Option {
+	return func(its *ITScopeCommunicator) {
+		its.maxWait = maxWait
+	}
+}
+
+// WithRequestHeaders adds headers to every request, overriding the standard
+// headers except Authorization, which yields ErrReservedHeader.
+func WithRequestHeaders(headers map[string]string) Option {
+	return func(its *ITScopeCommunicator) {
+		its.headers = make(map[string]string, len(headers))
+		for key, value := range headers {
+			its.headers[key] = value
+		}
+	}
+}
+
+// WithProgressCallback calls callback while the bodies of search and
+// product-types responses are read, with the bytes read so far and the
+// Content-Length, which is -1 if unknown.
+func WithProgressCallback(callback func(read, total int64)) Option {
+	return func(its *ITScopeCommunicator) {
+		its.progress = callback
+	}
+}
+
+// WithAPIVersion sets the ITScope API version used in all request paths,
+// "2.0" by default.
+func WithAPIVersion(version string) Option {
+	return func(its *ITScopeCommunicator) {
+		its.apiVersion = version
+	}
+}
+
+// WithQueryRewriter lets rewrite change every search query right before it
+// is escaped into the URL, after the supplier restriction of
+// WithDistributors was added. URL parameters such as page or realtime are
+// not part of the query.
+func WithQueryRewriter(rewrite func(query string) string) Option {
+	return func(its *ITScopeCommunicator) {
+		its.queryRewriter = rewrite
+	}
+}
+
+// WithCredentialProvider calls provider for the credentials of every request
+// instead of using the ones passed to New, e.g. to rotate between accounts.
+// provider is called concurrently and must be safe for that.
+func WithCredentialProvider(provider func() (username string, password string)) Option {
+	return func(its *ITScopeCommunicator) {
+		its.credentials = provider
+	}
+}
+
+const appTagHeader = "X-App-Tag"
+
+// WithAppTag sends tag in the X-App-Tag header of every request, so ITScope's
+// usage statistics can be broken down by calling application or feature.
+func WithAppTag(tag string) Option {
+	return func(its *ITScopeCommunicator) {
+		its.appTag = tag
+	}
+}
+
+// WithNormalizedImageURLs makes GetProductImages and
+// GetProductImagesDetailed return https:// URLs only, see NormalizeImageURL.
+// Invalid URLs are dropped. Without it the URLs are returned as delivered.
+func WithNormalizedImageURLs() Option {
+	return func(its *ITScopeCommunicator) {
+		its.normalizeImages = true
+	}
+}
+
+// WithDistributors restricts all searches to products offered by the given
+// distributors (supplier IDs) and removes the offers of all other
+// distributors from the returned products. By default all distributors are
+// included.
+func WithDistributors(ids ...string) Option {
+	return func(its *ITScopeCommunicator) {
+		its.distributors = make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			its.distributors[id] = struct{}{}
+		}
+	}
+}
+
+// WithReferenceDataTTL sets how long GetReferenceData keeps its result, 24
+// hours by default.
+func WithReferenceDataTTL(ttl time.Duration) Option {
+	return func(its *ITScopeCommunicator) {
+		its.references.ttl = ttl
+	}
+}
+
+// WithResponseCache caches search results for ttl, keyed by the request
+// signature. Realtime searches are never cached. Cached results are shared
+// and must be treated as read-only.
+func WithResponseCache(ttl time.Duration) Option {
+	return func(its *ITScopeCommunicator) {
+		if its.responses == nil {
+			its.responses = &responseCache{entries: make(map[string]responseCacheEntry)}
+		}
+		its.responses.ttl = ttl
+	}
+}
+
+// WithServeStaleOnError makes a failing search fall back to a cached result
+// that expired less than maxStaleAge ago. Such results have Stale set.
+// Without WithResponseCache results are kept for this fallback only.
+func WithServeStaleOnError(maxStaleAge time.Duration) Option {
+	return func(its *ITScopeCommunicator) {
+		if its.responses == nil {
+			its.responses = &responseCache{entries: make(map[string]responseCacheEntry)}
+		}
+		its.responses.maxStale = maxStaleAge
+	}
+}
+
+// WithDefaultTimeout bounds every request, including its retries, to timeout
+// when the caller's context has no deadline. Deadlines set by the caller
+// take precedence.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(its *ITScopeCommunicator) {
+		its.defaultTimeout = timeout
+	}
+}
+
+// ProductTypesOption configures a single GetAllProductTypes call.
+type ProductTypesOption func(params *productTypesParams)
+
+type productTypesParams struct {
+	representation  ProductTypeRepresentation
+	includeInactive bool
+}
+
+func newProductTypesParams(opts []ProductTypesOption) productTypesParams {
+	params := productTypesParams{
+		representation: ProductTypeReprStandard,
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	return params
+}
+
+// ProductTypeRepr selects the product-types representation. The detailed
+// representation additionally fills Description and ParentID.
+func ProductTypeRepr(representation ProductTypeRepresentation) ProductTypesOption {
+	return func(params *productTypesParams) {
+		params.representation = representation
+	}
+}
+
+// ProductTypeIncludeInactive also returns deprecated and hidden product
+// types, e.g. to resolve type IDs in historical data. See ProductType.Active.
+func ProductTypeIncludeInactive() ProductTypesOption {
+	return func(params *productTypesParams) {
+		params.includeInactive = true
+	}
+}
+
+// SearchOption configures a single product search.
+type SearchOption func(params *searchParams)
+
+type searchParams struct {
+	representation Representation
+	page           int
+	realtime       bool
+	fields         []string
+	sort           Sort
+}
+
+func newSearchParams(opts []SearchOption) searchParams {
+	params := searchParams{
+		representation: RepresentationStandard,
+		page:           1,
+		sort:           SortDefault,
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	return params
+}
+
+// SearchPage selects the result page, starting at 1.
+func SearchPage(page int) SearchOption {
+	return func(params *searchParams) {
+		params.page = max(page, 1)
+	}
+}
+
+// SearchRealtime makes ITScope query the distributors live instead of
+// returning cached prices and stock. Realtime searches are slow and count
+// more against the quota; they use the EndpointRealtime rate limiter.
+func SearchRealtime(realtime bool) SearchOption {
+	return func(params *searchParams) {
+		params.realtime = realtime
+	}
+}
+
+// SearchFields asks ITScope to return only the given fields (by their JSON
+// name, e.g. "puid", "price"). Fields that are not selected stay zero.
+func SearchFields(fields ...string) SearchOption {
+	return func(params *searchParams) {
+		params.fields = append(params.fields, fields...)
+	}
+}
+
+// SearchSort sets the order of the results, SortDefault if not given.
+func SearchSort(sort Sort) SearchOption {
+	return func(params *searchParams) {
+		params.sort = sort
+	}
+}
+
+// SearchRepr selects the product representation. RepresentationDetail adds
+// long texts such as LongDescription, MarketingText and FeatureBullets.
+func SearchRepr(representation Representation) SearchOption {
+	return func(params *searchParams) {
+		params.representation = representation
+	}
+}
+
+// OfferOption configures the offer selection of Product.BestOffer and
+// Product.CheapestPrice.
+type OfferOption func(params *offerParams)
+
+type offerParams struct {
+	includeOutOfStock bool
+}
+
+func newOfferParams(opts []OfferOption) offerParams {
+	params := offerParams{}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	return params
+}
+
+// IncludeOutOfStock considers offers of distributors without stock, e.g. for
+// reference pricing. By default only offers in stock are considered.
+func IncludeOutOfStock() OfferOption {
+	return func(params *offerParams) {
+		params.includeOutOfStock = true
+	}
+}
@@ -0,0 +1,140 @@
+package itscope
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// newHTTP2Communicator returns a communicator tuned with WithTransportTuning
+// that talks HTTP/2 to a local TLS server counting its HTTP/2 requests.
+func newHTTP2Communicator(tb testing.TB, http2Requests *atomic.Int64) *ITScopeCommunicator {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 {
+			http2Requests.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"product":[]}`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+
+	its := New("test", "user", "secret", German, WithTransportTuning(10, 10), WithoutRateLimit())
+	transport := its.client.Transport.(*http.Transport)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots, ServerName: "example.com"}
+	transport.DialContext = func(ctx context.Context, network string, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	return its
+}
+
+func TestTransportTuningReusesHTTP2Connection(t *testing.T) {
+	var http2Requests atomic.Int64
+	its := newHTTP2Communicator(t, &http2Requests)
+
+	var newConnections atomic.Int64
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if !info.Reused {
+				newConnections.Add(1)
+			}
+		},
+	})
+	const requests = 10
+	for i := 0; i < requests; i++ {
+		if err := its.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := newConnections.Load(); got != 1 {
+		t.Errorf("opened %d connections, want 1", got)
+	}
+	if got := http2Requests.Load(); got != requests {
+		t.Errorf("%d of %d requests used HTTP/2", got, requests)
+	}
+}
+
+func BenchmarkTransportTuningConcurrentRequests(b *testing.B) {
+	var http2Requests atomic.Int64
+	its := newHTTP2Communicator(b, &http2Requests)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := its.Ping(context.Background()); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func TestRetriesDisabled(t *testing.T) {
+	for name, test := range map[string]struct {
+		opt   Option
+		calls int64
+	}{
+		"WithNoRetry":       {opt: WithNoRetry(), calls: 1},
+		"WithMaxRetries(0)": {opt: WithMaxRetries(0), calls: 1},
+		"WithMaxRetries(2)": {opt: WithMaxRetries(2), calls: 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int64
+			its := newTestCommunicator(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+				calls.Add(1)
+				return jsonResponse(request, http.StatusInternalServerError, ""), nil
+			}), test.opt)
+
+			_, err := its.GetProductsFromQuery(context.Background(), "puid=1")
+			if !errors.Is(err, UnexpectedStatusCodeError{StatusCode: http.StatusInternalServerError}) {
+				t.Errorf("got %v, want status 500", err)
+			}
+			if got := calls.Load(); got != test.calls {
+				t.Errorf("got %d calls, want %d", got, test.calls)
+			}
+		})
+	}
+}
+
+func TestRetryableStatusCodesIgnoresInvalidCodes(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	its := newTestCommunicator(nil, WithRetryableStatusCodes(503, 42, 700), WithLogger(logger))
+
+	if !reflect.DeepEqual(its.retryableStatus, map[int]struct{}{503: {}}) {
+		t.Errorf("got retryable status codes %v, want only 503", its.retryableStatus)
+	}
+	if len(hook.Entries) != 2 {
+		t.Errorf("logged %d entries, want one per invalid code", len(hook.Entries))
+	}
+}
+
+func TestPriorityRequestsKeepEndpointRateLimit(t *testing.T) {
+	its := newTestCommunicator(nil, WithEndpointRateLimit(EndpointRealtime, 1, 1), WithPriorityRateLimit(10, 10))
+	ctx := ContextWithPriority(context.Background())
+
+	realtime := its.limitersFor(ctx, EndpointRealtime)
+	if len(realtime) != 2 || realtime[0] != its.priority || realtime[1] != its.limiters[EndpointRealtime] {
+		t.Errorf("priority realtime requests wait for %v, want the priority and the realtime limiter", realtime)
+	}
+	search := its.limitersFor(ctx, EndpointSearch)
+	if len(search) != 1 || search[0] != its.priority {
+		t.Errorf("priority searches wait for %v, want only the priority limiter", search)
+	}
+	if normal := its.limitersFor(context.Background(), EndpointSearch); len(normal) != 1 || normal[0] != its.limiter {
+		t.Errorf("searches wait for %v, want only the shared limiter", normal)
+	}
+}
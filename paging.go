@@ -0,0 +1,70 @@
+package itscope
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetProductsPageCursor returns one page of query and the cursor of the next
+// page, which is "" after the last page. Pass "" to start.
+//
+// ITScope only supports offset paging, so the cursor merely encodes the next
+// page number and the number of products returned so far: if the catalog
+// changes during a crawl, products may still be skipped or returned twice.
+// Deduplicate by Puid if that matters.
+func (its *ITScopeCommunicator) GetProductsPageCursor(ctx context.Context, query string, cursor string) (*ProductsContainer, string, error) {
+	page, seen, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	container, err := its.GetProductsFromQuery(ctx, query, SearchPage(page))
+	if err != nil {
+		return nil, "", fmt.Errorf("GetProductsPageCursor: %w", err)
+	}
+
+	seen += len(container.Product)
+	if len(container.Product) == 0 || seen >= container.TotalProductCount {
+		return container, "", nil
+	}
+
+	return container, encodeCursor(page+1, seen), nil
+}
+
+func encodeCursor(page int, seen int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("page:" + strconv.Itoa(page) + ":" + strconv.Itoa(seen)))
+}
+
+// decodeCursor returns the page and the number of products already returned.
+// Cursors without that number decode with seen 0.
+func decodeCursor(cursor string) (page int, seen int, err error) {
+	if cursor == "" {
+		return 1, 0, nil
+	}
+
+	invalid := fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, invalid
+	}
+	rest, ok := strings.CutPrefix(string(data), "page:")
+	if !ok {
+		return 0, 0, invalid
+	}
+	number, count, hasCount := strings.Cut(rest, ":")
+	page, err = strconv.Atoi(number)
+	if err != nil || page < 1 {
+		return 0, 0, invalid
+	}
+	if hasCount {
+		seen, err = strconv.Atoi(count)
+		if err != nil || seen < 0 {
+			return 0, 0, invalid
+		}
+	}
+
+	return page, seen, nil
+}
@@ -0,0 +1,123 @@
+package itscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pagedTransport serves total products in pages of pageSize and records the
+// requested page numbers in pages.
+func pagedTransport(total int, pageSize int, truncated bool, pages *[]int) roundTripFunc {
+	var mu sync.Mutex
+	return func(request *http.Request) (*http.Response, error) {
+		page, err := strconv.Atoi(request.URL.Query().Get("page"))
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		*pages = append(*pages, page)
+		mu.Unlock()
+
+		var products []string
+		for i := (page - 1) * pageSize; i < total && i < page*pageSize; i++ {
+			products = append(products, fmt.Sprintf(`{"puid":"%d"}`, i))
+		}
+		body := fmt.Sprintf(`{"product":[%s],"totalProductCount":%d,"truncated":%t}`, strings.Join(products, ","), total, truncated)
+		return jsonResponse(request, http.StatusOK, body), nil
+	}
+}
+
+func TestGetProductsPageCursorStopsAfterLastPage(t *testing.T) {
+	const total = 250
+	var pages []int
+	its := newTestCommunicator(pagedTransport(total, 100, false, &pages))
+
+	received := 0
+	cursor := ""
+	for i := 0; i < 10; i++ {
+		container, next, err := its.GetProductsPageCursor(context.Background(), "manufacturer=HP", cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received += len(container.Product)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if received != total {
+		t.Errorf("received %d products, want %d", received, total)
+	}
+	if len(pages) != 3 {
+		t.Errorf("requested pages %v, want [1 2 3]", pages)
+	}
+}
+
+func TestDecodeCursorWithoutCount(t *testing.T) {
+	page, seen, err := decodeCursor("cGFnZTo0") // "page:4"
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != 4 || seen != 0 {
+		t.Errorf("got page %d, seen %d, want page 4, seen 0", page, seen)
+	}
+}
+
+func TestTruncatedResultsAreReported(t *testing.T) {
+	var pages []int
+	its := newTestCommunicator(pagedTransport(250, 100, true, &pages))
+
+	products, err := its.GetAllProductsConcurrent(context.Background(), "manufacturer=HP", 2)
+	if !errors.Is(err, ErrResultsTruncated) || len(products) != 250 {
+		t.Errorf("GetAllProductsConcurrent returned %d products, %v, want 250 with ErrResultsTruncated", len(products), err)
+	}
+
+	received, crawl, err := its.ResumeCrawl(context.Background(), CrawlState{Query: "manufacturer=HP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for range received {
+		count++
+	}
+	if !errors.Is(crawl.Err(), ErrResultsTruncated) || count != 250 || !crawl.State().Done {
+		t.Errorf("ResumeCrawl delivered %d products, %v, want 250 with ErrResultsTruncated", count, crawl.Err())
+	}
+}
+
+func TestGetAllProductsFetchesMinimumPages(t *testing.T) {
+	for name, test := range map[string]struct {
+		maxResults   int
+		wantProducts int
+		wantPages    []int
+	}{
+		"within first page": {maxResults: 30, wantProducts: 30, wantPages: []int{1}},
+		"spanning pages":    {maxResults: 150, wantProducts: 150, wantPages: []int{1, 2}},
+		"exactly two pages": {maxResults: 200, wantProducts: 200, wantPages: []int{1, 2}},
+		"unlimited":         {maxResults: 0, wantProducts: 250, wantPages: []int{1, 2, 3}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var pages []int
+			its := newTestCommunicator(pagedTransport(250, 100, false, &pages))
+
+			products, err := its.GetAllProducts(context.Background(), "manufacturer=HP", test.maxResults)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(products) != test.wantProducts {
+				t.Errorf("got %d products, want %d", len(products), test.wantProducts)
+			}
+			if !reflect.DeepEqual(pages, test.wantPages) {
+				t.Errorf("requested pages %v, want %v", pages, test.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package itscope
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var productJSONFields = jsonFieldIndex(reflect.TypeOf(Product{}))
+
+var durationPattern = regexp.MustCompile(`(?i)(\d+)\s*-?\s*(years?|yrs?|jahre?n?|months?|mo|monate?n?)\b`)
+
+// ServiceDurationMonths returns the duration of a service or warranty product
+// in months. ServiceDuration is preferred; a duration mentioned in
+// WarrantyText ("3 Jahre", "36 months") is used as fallback. It returns 0 if
+// no duration is known.
+func (p *Product) ServiceDurationMonths() int {
+	if months, ok := parseDurationMonths(p.ServiceDuration); ok {
+		return months
+	}
+	if months, ok := parseDurationMonths(p.WarrantyText); ok {
+		return months
+	}
+
+	return 0
+}
+
+func parseDurationMonths(text string) (int, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, false
+	}
+	if months, err := strconv.Atoi(text); err == nil {
+		return months, true
+	}
+
+	match := durationPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	unit := strings.ToLower(match[2])
+	if strings.HasPrefix(unit, "y") || strings.HasPrefix(unit, "j") {
+		return value * 12, true
+	}
+
+	return value, true
+}
+
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type product Product
+	err := json.Unmarshal(data, (*product)(p))
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	for key := range fields {
+		if _, ok := productJSONFields[strings.ToLower(key)]; ok {
+			delete(fields, key)
+		}
+	}
+
+	p.Extra = nil
+	if len(fields) > 0 {
+		p.Extra = fields
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the product with the fields held in Extra, so products
+// decoded from ITScope responses are re-encoded without losing unmapped data.
+func (p Product) MarshalJSON() ([]byte, error) {
+	type product Product
+	data, err := json.Marshal(product(p))
+	if err != nil || len(p.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range p.Extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// MarshalJSON encodes the product with its type in the "type" field. Without
+// it the promoted Product.MarshalJSON would drop Type.
+func (p ProductWithType) MarshalJSON() ([]byte, error) {
+	data, err := p.Product.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	productType, err := json.Marshal(p.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	fields["type"] = productType
+
+	return json.Marshal(fields)
+}
+
+func (p *ProductWithType) UnmarshalJSON(data []byte) error {
+	err := p.Product.UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	delete(p.Extra, "type")
+	if len(p.Extra) == 0 {
+		p.Extra = nil
+	}
+
+	var productType struct {
+		Type *ProductType `json:"type"`
+	}
+	err = json.Unmarshal(data, &productType)
+	if err != nil {
+		return err
+	}
+	p.Type = productType.Type
+
+	return nil
+}
+
+// Classifications returns the eCl@ss, ETIM and UNSPSC codes of the product
+// that are set.
+func (p *Product) Classifications() []Classification {
+	classifications := make([]Classification, 0, 3)
+	for _, classification := range []Classification{
+		{Scheme: ClassificationECLASS, Code: p.EClass},
+		{Scheme: ClassificationETIM, Code: p.EtimClass},
+		{Scheme: ClassificationUNSPSC, Code: p.Unspsc},
+	} {
+		classification.Code = strings.TrimSpace(classification.Code)
+		if classification.Code != "" {
+			classifications = append(classifications, classification)
+		}
+	}
+
+	return classifications
+}
+
+// PrimaryImage returns the image to show for the product: Image1, else the
+// first other image including the thumbnail, or "" if there is none.
+func (p *Product) PrimaryImage() string {
+	for _, image := range []string{p.Image1, p.Image2, p.Image3, p.Image4, p.Image5, p.ImageHighRes1, p.ImageThumb} {
+		if image = strings.TrimSpace(image); image != "" {
+			return image
+		}
+	}
+
+	return ""
+}
+
+// Rating returns the average rating and the number of ratings of the
+// product. ITScope only returns ratings in the detail representation and not
+// for every product; unrated products yield 0, 0.
+func (p *Product) Rating() (float64, int) {
+	return parseFloat(p.RatingAverage), int(parseInt(p.RatingCount))
+}
+
+// ModifiedAt returns when the product data last changed. ok is false if
+// LastModified is missing or not a known date format.
+func (p *Product) ModifiedAt() (modified time.Time, ok bool) {
+	return parseTime(p.LastModified)
+}
+
+// DealActive reports whether the deal of the product is valid at now. Open
+// ends of the validity window are treated as unbounded.
+func (p *Product) DealActive(now time.Time) bool {
+	if from, ok := parseTime(p.DealValidFrom); ok && now.Before(from) {
+		return false
+	}
+	if to, ok := parseTime(p.DealValidTo); ok && now.After(to) {
+		return false
+	}
+
+	return true
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+}
+
+// parseTime parses the date formats used in ITScope responses.
+func parseTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,81 @@
+package itscope
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const productsFixture = `{
+	"product": [
+		{
+			"puid": "1000001",
+			"ean": "4006381333931",
+			"manufacturerSKU": "AB-123",
+			"manufacturerName": "HP",
+			"productNameWithManufacturer": "HP LaserJet Pro",
+			"productTypeId": "PRN",
+			"price": "199,90",
+			"currencyCode": "EUR",
+			"stock": "12",
+			"supplierItems": [{"supplierId": "42", "supplierSKU": "X-1", "price": "189,90", "stock": "3"}],
+			"attributes": [{"displayValue": "A4", "attributeTypeId": 7, "attributeTypeName": "Format"}],
+			"accessories": [{"referencedProductId": "1000002", "typeId": "ACC", "type": "accessory"}],
+			"energyEfficiencyClass": "A+",
+			"vendorData": {"warehouse":"DE-1","flags":[1,2,3]}
+		},
+		{
+			"puid": "1000002",
+			"shortDescription": "Toner",
+			"newFlag": true
+		}
+	],
+	"totalProductCount": 2
+}`
+
+func TestProductJSONRoundTrip(t *testing.T) {
+	var decoded ProductsContainer
+	if err := json.Unmarshal([]byte(productsFixture), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Product[0].Extra) != 2 || len(decoded.Product[1].Extra) != 1 {
+		t.Fatalf("got extra fields %v and %v", decoded.Product[0].Extra, decoded.Product[1].Extra)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped ProductsContainer
+	if err := json.Unmarshal(encoded, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, roundTripped) {
+		t.Errorf("round trip changed the products:\n got %+v\nwant %+v", roundTripped, decoded)
+	}
+}
+
+func TestProductWithTypeJSONRoundTrip(t *testing.T) {
+	var container ProductsContainer
+	if err := json.Unmarshal([]byte(productsFixture), &container); err != nil {
+		t.Fatal(err)
+	}
+	withType := ProductWithType{
+		Product: container.Product[0],
+		Type:    &ProductType{ID: "PRN", Name: "Printer", Active: true, ProductTypeGroup: ProductTypeGroup{ID: "HW", Name: "Hardware"}},
+	}
+
+	encoded, err := json.Marshal(withType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped ProductWithType
+	if err := json.Unmarshal(encoded, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(withType, roundTripped) {
+		t.Errorf("round trip changed the product:\n got %+v\nwant %+v", roundTripped, withType)
+	}
+}
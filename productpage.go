@@ -0,0 +1,92 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ProductPage bundles what is needed to render a single product.
+type ProductPage struct {
+	Product     *Product
+	Images      []string
+	Accessories []Product
+}
+
+// ProductPageOptions controls the additional requests made by GetProductPage.
+type ProductPageOptions struct {
+	// ValidateImages drops images that do not answer a HEAD request with a
+	// 2xx status. Without it the images are returned unchecked.
+	ValidateImages bool
+	// Accessories fetches the accessories of the product.
+	Accessories bool
+}
+
+// GetProductByPUID returns the product with puid in the detail
+// representation, or ErrNotFound if there is none.
+func (its *ITScopeCommunicator) GetProductByPUID(ctx context.Context, puid string) (*Product, error) {
+	product, err := its.GetProductDetail(ctx, puid)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductByPUID: %w", err)
+	}
+	if product == nil {
+		return nil, fmt.Errorf("GetProductByPUID: %w: %s", ErrNotFound, puid)
+	}
+
+	return product, nil
+}
+
+// GetValidProductImages returns the images of product that answer a HEAD
+// request with a 2xx status. Image hosts are not rate limited.
+func (its *ITScopeCommunicator) GetValidProductImages(ctx context.Context, product *Product) ([]string, error) {
+	images := its.GetProductImages(product)
+	valid := make([]string, 0, len(images))
+	for _, image := range images {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodHead, image, nil)
+		if err != nil {
+			continue
+		}
+		response, err := its.client.Do(request)
+		if err != nil {
+			continue
+		}
+		response.Body.Close()
+		if response.StatusCode >= 200 && response.StatusCode <= 299 {
+			valid = append(valid, image)
+		}
+	}
+
+	return valid, nil
+}
+
+// GetProductPage returns the product with puid and, depending on options,
+// its validated images and accessories.
+func (its *ITScopeCommunicator) GetProductPage(ctx context.Context, puid string, options ProductPageOptions) (*ProductPage, error) {
+	product, err := its.GetProductByPUID(ctx, puid)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductPage: %w", err)
+	}
+
+	page := &ProductPage{Product: product}
+	if options.ValidateImages {
+		page.Images, err = its.GetValidProductImages(ctx, product)
+		if err != nil {
+			return nil, fmt.Errorf("GetProductPage: %w", err)
+		}
+	} else {
+		page.Images = its.GetProductImages(product)
+	}
+
+	if options.Accessories {
+		page.Accessories, err = its.GetProductAccessories(ctx, product)
+		if err != nil {
+			return nil, fmt.Errorf("GetProductPage: %w", err)
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,180 @@
+package itscope
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// UnmarshalJSON decodes a product type; types without an "active" field are
+// active.
+func (pt *ProductType) UnmarshalJSON(data []byte) error {
+	type productType ProductType
+	decoded := productType{Active: true}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+	*pt = ProductType(decoded)
+
+	return nil
+}
+
+// UnmarshalXML decodes a product type; types without an "active" element are
+// active.
+func (pt *ProductType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type productType ProductType
+	decoded := productType{Active: true}
+	err := d.DecodeElement(&decoded, &start)
+	if err != nil {
+		return err
+	}
+	*pt = ProductType(decoded)
+
+	return nil
+}
+
+// IconURL returns the icon of the product type from the detailed
+// representation as an absolute URL, or "" if there is none.
+func (pt *ProductType) IconURL() string {
+	icon := strings.TrimSpace(pt.Icon)
+	if strings.HasPrefix(icon, "//") {
+		return "https:" + icon
+	}
+	return icon
+}
+
+// GetProductTypeIcons maps the IDs of the given product types to their icon
+// URLs, skipping types without an icon.
+func (its *ITScopeCommunicator) GetProductTypeIcons(productTypes []ProductType) map[string]string {
+	icons := make(map[string]string)
+	for _, productType := range productTypes {
+		if icon := productType.IconURL(); icon != "" {
+			icons[productType.ID] = icon
+		}
+	}
+
+	return icons
+}
+
+// BuildProductTypeTree nests product types below their parent via ParentID.
+// Types whose parent is missing from types become roots, as do types caught
+// in a parent cycle. Siblings keep the order of types.
+func (its *ITScopeCommunicator) BuildProductTypeTree(types []ProductType) []ProductTypeNode {
+	known := make(map[string]struct{}, len(types))
+	for _, productType := range types {
+		known[productType.ID] = struct{}{}
+	}
+
+	children := make(map[string][]int)
+	roots := make([]int, 0)
+	for i, productType := range types {
+		_, hasParent := known[productType.ParentID]
+		if productType.ParentID == "" || productType.ParentID == productType.ID || !hasParent {
+			roots = append(roots, i)
+			continue
+		}
+		children[productType.ParentID] = append(children[productType.ParentID], i)
+	}
+
+	visited := make([]bool, len(types))
+	var build func(i int) ProductTypeNode
+	build = func(i int) ProductTypeNode {
+		visited[i] = true
+		node := ProductTypeNode{ProductType: types[i]}
+		for _, child := range children[types[i].ID] {
+			if !visited[child] {
+				node.Children = append(node.Children, build(child))
+			}
+		}
+		return node
+	}
+
+	tree := make([]ProductTypeNode, 0, len(roots))
+	for _, root := range roots {
+		if !visited[root] {
+			tree = append(tree, build(root))
+		}
+	}
+	for i := range types {
+		if !visited[i] {
+			tree = append(tree, build(i))
+		}
+	}
+
+	return tree
+}
+
+// GetGroupOverview returns the product types of the group groupID with the
+// number of products of each type. The product types come from the
+// product-type cache when enabled; the counts cost one request per type.
+func (its *ITScopeCommunicator) GetGroupOverview(ctx context.Context, groupID string) (GroupOverview, error) {
+	productTypes, err := its.cachedProductTypes(ctx)
+	if err != nil {
+		return GroupOverview{}, fmt.Errorf("GetGroupOverview: %w", err)
+	}
+
+	groupTypes := its.FilterProductTypesByGroupId(groupID, productTypes)
+	overview := GroupOverview{
+		Group: ProductTypeGroup{ID: groupID},
+		Types: make([]ProductTypeOverview, 0, len(groupTypes)),
+	}
+	for _, productType := range groupTypes {
+		overview.Group = productType.ProductTypeGroup
+
+		count, err := its.CountProducts(ctx, "producttype="+productType.ID)
+		if err != nil {
+			return GroupOverview{}, fmt.Errorf("GetGroupOverview: %w", err)
+		}
+		overview.Types = append(overview.Types, ProductTypeOverview{ProductType: productType, ProductCount: count})
+	}
+
+	return overview, nil
+}
+
+// GetAllProductTypesMultiLang fetches the product types in each of languages
+// concurrently, using ContextWithLanguage, and returns them by language.
+func (its *ITScopeCommunicator) GetAllProductTypesMultiLang(ctx context.Context, languages []Language, opts ...ProductTypesOption) (map[Language][]ProductType, error) {
+	ctx, done, err := its.beginOperation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllProductTypesMultiLang: %w", err)
+	}
+	defer done()
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var once sync.Once
+	var workerErr error
+	result := make(map[Language][]ProductType, len(languages))
+	for _, language := range languages {
+		language := language
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			productTypes, err := its.GetAllProductTypes(ContextWithLanguage(workerCtx, language), opts...)
+			if err != nil {
+				once.Do(func() {
+					workerErr = fmt.Errorf("%s: %w", language, err)
+					cancel()
+				})
+				return
+			}
+			mu.Lock()
+			result[language] = productTypes
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if workerErr != nil {
+		return nil, fmt.Errorf("GetAllProductTypesMultiLang: %w", workerErr)
+	}
+
+	return result, nil
+}
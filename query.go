@@ -0,0 +1,332 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxQueryLength is the longest escaped query segment sent to the search
+// endpoint, leaving room for the rest of the URL within common 2k limits.
+const maxQueryLength = 1800
+
+// SearchField is a filter key of the ITScope search.
+type SearchField struct {
+	Key         string
+	Description string
+}
+
+// SearchableFields lists the search filter keys used by this package.
+// ITScope offers no endpoint to list them, so the list is maintained by hand
+// and not exhaustive.
+var SearchableFields = []SearchField{
+	{Key: "search", Description: "full-text search"},
+	{Key: "puid", Description: "ITScope product ID"},
+	{Key: "id", Description: "ITScope product ID, used for bulk lookups"},
+	{Key: "distpid", Description: "distributor SKU"},
+	{Key: "mpn", Description: "manufacturer part number"},
+	{Key: "producttype", Description: "product type ID"},
+	{Key: "supplierid", Description: "distributor ID"},
+	{Key: "specialoffer", Description: "products on special offer, true or false"},
+	{Key: "pricetype", Description: "price basis, see PriceType"},
+	{Key: "modifiedsince", Description: "products modified since an RFC 3339 time"},
+}
+
+// ChunkByURLLength joins values into as few strings of the form
+// prefix+value+sep+prefix+value as possible, each at most maxURLLen
+// characters long once URL-escaped. A value too long on its own gets a chunk
+// of its own, which exceeds the limit.
+func ChunkByURLLength(prefix string, values []string, sep string, maxURLLen int) []string {
+	chunks := make([]string, 0)
+	var current strings.Builder
+	for _, value := range values {
+		part := prefix + value
+		if current.Len() > 0 && len(url.QueryEscape(current.String()+sep+part)) > maxURLLen {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(part)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// ProductQuery builds ITScope search queries. Criteria are joined with ";";
+// repeating a key ORs its values, different keys must all match.
+type ProductQuery struct {
+	terms []queryTerm
+	err   error
+}
+
+type queryTerm struct {
+	key    string
+	values []string
+}
+
+func NewProductQuery() *ProductQuery {
+	return &ProductQuery{}
+}
+
+// Where adds a criterion that must match.
+func (q *ProductQuery) Where(key string, value string) *ProductQuery {
+	return q.AnyOf(key, value)
+}
+
+// AnyOf adds an OR group which matches if key equals any of values. A key
+// or value containing ";" or "=" is not added; Build and Split then return
+// ErrInvalidFilter.
+func (q *ProductQuery) AnyOf(key string, values ...string) *ProductQuery {
+	for _, value := range values {
+		if err := checkFilter(key, value); err != nil {
+			if q.err == nil {
+				q.err = err
+			}
+			return q
+		}
+	}
+	if len(values) > 0 {
+		q.terms = append(q.terms, queryTerm{key: key, values: values})
+	}
+	return q
+}
+
+// ProductTypes restricts the query to products of any of the given product
+// type IDs. Long lists are distributed over several requests by Split.
+func (q *ProductQuery) ProductTypes(ids ...string) *ProductQuery {
+	return q.AnyOf("producttype", ids...)
+}
+
+// PriceType selects the price basis of the returned prices, reported back in
+// Product.PriceType. Without it ITScope uses the account's default.
+func (q *ProductQuery) PriceType(priceType PriceType) *ProductQuery {
+	return q.Where("pricetype", string(priceType))
+}
+
+// String serializes the query without checking its length or reporting
+// rejected criteria.
+func (q *ProductQuery) String() string {
+	return serializeTerms(q.terms)
+}
+
+// Build serializes the query and returns ErrQueryTooLong if it would not fit
+// into a single request.
+func (q *ProductQuery) Build() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	query := q.String()
+	if len(url.QueryEscape(query)) > maxQueryLength {
+		return "", fmt.Errorf("%w: %d characters", ErrQueryTooLong, len(url.QueryEscape(query)))
+	}
+	return query, nil
+}
+
+// Split serializes the query into as many queries as needed to keep each one
+// below maxLength escaped characters by distributing the values of the
+// largest OR group. The union of their results equals the result of the
+// whole query.
+func (q *ProductQuery) Split(maxLength int) ([]string, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	query := q.String()
+	if len(url.QueryEscape(query)) <= maxLength {
+		return []string{query}, nil
+	}
+
+	largest := -1
+	for i, term := range q.terms {
+		if len(term.values) > 1 && (largest < 0 || len(term.values) > len(q.terms[largest].values)) {
+			largest = i
+		}
+	}
+	if largest < 0 {
+		return nil, fmt.Errorf("%w: %d characters", ErrQueryTooLong, len(url.QueryEscape(query)))
+	}
+
+	terms := make([]queryTerm, len(q.terms))
+	copy(terms, q.terms)
+	group := q.terms[largest]
+	fits := func(values []string) bool {
+		terms[largest] = queryTerm{key: group.key, values: values}
+		return len(url.QueryEscape(serializeTerms(terms))) <= maxLength
+	}
+
+	queries := make([]string, 0)
+	current := make([]string, 0)
+	for _, value := range group.values {
+		if fits(append(current[:len(current):len(current)], value)) {
+			current = append(current, value)
+			continue
+		}
+		if len(current) == 0 {
+			return nil, fmt.Errorf("%w: value %q of %q does not fit", ErrQueryTooLong, value, group.key)
+		}
+		fits(current)
+		queries = append(queries, serializeTerms(terms))
+		current = []string{value}
+		if !fits(current) {
+			return nil, fmt.Errorf("%w: value %q of %q does not fit", ErrQueryTooLong, value, group.key)
+		}
+	}
+	fits(current)
+	queries = append(queries, serializeTerms(terms))
+
+	return queries, nil
+}
+
+func serializeTerms(terms []queryTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		for _, value := range term.values {
+			parts = append(parts, term.key+"="+value)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseFilters parses a query of ";"-separated key=value filters. A filter
+// without "=" is kept as key with an empty value.
+func ParseFilters(query string) url.Values {
+	filters := url.Values{}
+	for _, part := range strings.Split(query, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		filters.Add(key, value)
+	}
+	return filters
+}
+
+// SerializeFilters joins filters into a query as sent to ITScope, sorted by
+// key. Keys with an empty value are sent without "=". Keys and values
+// containing ";" or "=" would change the meaning of the query and are
+// rejected with ErrInvalidFilter.
+func SerializeFilters(filters url.Values) (string, error) {
+	return serializeFilters(filters, nil)
+}
+
+// filterKey is a key of a parsed query in the order of its first appearance.
+// Bare keys were given without "=".
+type filterKey struct {
+	name string
+	bare bool
+}
+
+// filterOrder returns the keys of query in the order they appear in it, to
+// serialize its parsed filters the way they were given.
+func filterOrder(query string) []filterKey {
+	seen := make(map[string]struct{})
+	order := make([]filterKey, 0)
+	for _, part := range strings.Split(query, ";") {
+		if part == "" {
+			continue
+		}
+		key, _, hasValue := strings.Cut(part, "=")
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		order = append(order, filterKey{name: key, bare: !hasValue})
+	}
+	return order
+}
+
+// serializeFilters is SerializeFilters with the keys in order first, then the
+// remaining keys sorted.
+func serializeFilters(filters url.Values, order []filterKey) (string, error) {
+	keys := make([]filterKey, 0, len(filters))
+	ordered := make(map[string]struct{}, len(order))
+	for _, key := range order {
+		if _, ok := filters[key.name]; ok {
+			keys = append(keys, key)
+			ordered[key.name] = struct{}{}
+		}
+	}
+	remaining := make([]string, 0, len(filters)-len(keys))
+	for key := range filters {
+		if _, ok := ordered[key]; !ok {
+			remaining = append(remaining, key)
+		}
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		keys = append(keys, filterKey{name: key, bare: true})
+	}
+
+	parts := make([]string, 0, len(filters))
+	for _, key := range keys {
+		for _, value := range filters[key.name] {
+			if err := checkFilter(key.name, value); err != nil {
+				return "", err
+			}
+			if value == "" && key.bare {
+				parts = append(parts, key.name)
+			} else {
+				parts = append(parts, key.name+"="+value)
+			}
+		}
+	}
+	return strings.Join(parts, ";"), nil
+}
+
+// checkFilter returns ErrInvalidFilter if key or value cannot be sent as a
+// single key=value filter.
+func checkFilter(key string, value string) error {
+	if key == "" || strings.ContainsAny(key, ";=") {
+		return fmt.Errorf("%w: key %q", ErrInvalidFilter, key)
+	}
+	if strings.ContainsAny(value, ";=") {
+		return fmt.Errorf("%w: value %q of %q", ErrInvalidFilter, value, key)
+	}
+	return nil
+}
+
+// GetProductsFromProductQuery runs q, splitting it into several requests if
+// it is too long for one, and merges the results deduplicated by Puid.
+func (its *ITScopeCommunicator) GetProductsFromProductQuery(ctx context.Context, q *ProductQuery, opts ...SearchOption) (*ProductsContainer, error) {
+	ctx, done, err := its.beginOperation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsFromProductQuery: %w", err)
+	}
+	defer done()
+
+	queries, err := q.Split(maxQueryLength)
+	if err != nil {
+		return nil, fmt.Errorf("GetProductsFromProductQuery: %w", err)
+	}
+
+	merged := &ProductsContainer{Product: make([]Product, 0)}
+	seen := make(map[string]struct{})
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		container, err := its.GetProductsFromQuery(ctx, query, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("GetProductsFromProductQuery: %w", err)
+		}
+
+		merged.TotalProductCount += container.TotalProductCount
+		merged.Truncated = merged.Truncated || container.Truncated
+		for _, product := range container.Product {
+			if _, ok := seen[product.Puid]; ok {
+				continue
+			}
+			seen[product.Puid] = struct{}{}
+			merged.Product = append(merged.Product, product)
+		}
+	}
+
+	return merged, nil
+}
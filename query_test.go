@@ -0,0 +1,117 @@
+package itscope
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkByURLLength(t *testing.T) {
+	long := strings.Repeat("x", 20)
+	for name, test := range map[string]struct {
+		values []string
+		max    int
+		want   []string
+	}{
+		"empty":              {values: nil, max: 100, want: []string{}},
+		"fits exactly":       {values: []string{"aa", "bb"}, max: len("id%3Daa%3Bid%3Dbb"), want: []string{"id=aa;id=bb"}},
+		"one over the limit": {values: []string{"aa", "bb"}, max: len("id%3Daa%3Bid%3Dbb") - 1, want: []string{"id=aa", "id=bb"}},
+		"packs greedily":     {values: []string{"a", "b", "c", "d", "e"}, max: len("id%3Da%3Bid%3Db"), want: []string{"id=a;id=b", "id=c;id=d", "id=e"}},
+		"oversize value":     {values: []string{"a", long, "b"}, max: 10, want: []string{"id=a", "id=" + long, "id=b"}},
+		"escaped separators": {values: []string{"a;b", "c=d"}, max: len("id%3Da%3Bb%3Bid%3Dc%3Dd") - 1, want: []string{"id=a;b", "id=c=d"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := ChunkByURLLength("id=", test.values, ";", test.max)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCreateQueryStringsStaysBelowMaxQueryLength(t *testing.T) {
+	ids := make([]string, 120)
+	for i := range ids {
+		ids[i] = strings.Repeat(string(rune('a'+i%26)), 60)
+	}
+
+	its := &ITScopeCommunicator{}
+	queries := its.createQueryStrings("id", ids, 50)
+	count := 0
+	for _, query := range queries {
+		if length := len(url.QueryEscape(query)); length > maxQueryLength {
+			t.Errorf("query has %d escaped characters, want at most %d", length, maxQueryLength)
+		}
+		count += strings.Count(query, "id=")
+	}
+	if count != len(ids) {
+		t.Errorf("queries contain %d IDs, want %d", count, len(ids))
+	}
+	if len(queries) <= 3 {
+		t.Errorf("got %d queries, expected long IDs to need more than the 3 count-based chunks", len(queries))
+	}
+}
+
+func TestSerializeFiltersRejectsSeparators(t *testing.T) {
+	invalid := []url.Values{
+		{"manufacturer": {"HP;supplierid=99"}},
+		{"manufacturer": {"a=b"}},
+		{"manu;facturer": {"HP"}},
+		{"": {"HP"}},
+	}
+	for _, filters := range invalid {
+		if query, err := SerializeFilters(filters); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("SerializeFilters(%v) = %q, %v, want ErrInvalidFilter", filters, query, err)
+		}
+	}
+
+	query, err := SerializeFilters(url.Values{"producttype": {"A", "B"}, "manufacturer": {"HP"}, "specialoffer": {""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "manufacturer=HP;producttype=A;producttype=B;specialoffer"; query != want {
+		t.Errorf("got %q, want %q", query, want)
+	}
+}
+
+func TestSerializeFiltersKeepsQueryOrder(t *testing.T) {
+	for _, query := range []string{"distpid=abc;manufacturer=HP;id=", "supplierid=12;search"} {
+		got, err := serializeFilters(ParseFilters(query), filterOrder(query))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != query {
+			t.Errorf("got %q, want %q", got, query)
+		}
+	}
+}
+
+func TestProductQueryRejectsSeparators(t *testing.T) {
+	for name, q := range map[string]*ProductQuery{
+		"semicolon in value": NewProductQuery().Where("manufacturer", "HP;supplierid=99"),
+		"equals in value":    NewProductQuery().AnyOf("manufacturer", "HP", "a=b"),
+		"separator in key":   NewProductQuery().Where("manufacturer;id", "HP"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if query, err := q.Build(); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("Build() = %q, %v, want ErrInvalidFilter", query, err)
+			}
+			if queries, err := q.Split(maxQueryLength); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("Split() = %q, %v, want ErrInvalidFilter", queries, err)
+			}
+			if query := q.String(); strings.Contains(query, "supplierid") || strings.Contains(query, "a=b") {
+				t.Errorf("String() = %q contains the rejected criterion", query)
+			}
+		})
+	}
+
+	query, err := NewProductQuery().AnyOf("manufacturer", "HP", "Dell").Where("specialoffer", "true").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "manufacturer=HP;manufacturer=Dell;specialoffer=true"; query != want {
+		t.Errorf("got %q, want %q", query, want)
+	}
+}
@@ -0,0 +1,35 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// GetQuotaStatus returns the API usage of the account within the current
+// quota period. Accounts without a quota answer with 404, returned as
+// NotFound error.
+func (its *ITScopeCommunicator) GetQuotaStatus(ctx context.Context) (*QuotaStatus, error) {
+	format := its.format
+	request, err := its.newRequest(ctx, http.MethodGet, its.buildURL("account/quota."+string(format), nil), nil, format)
+	if err != nil {
+		return nil, fmt.Errorf("GetQuotaStatus: %w", err)
+	}
+
+	response, err := its.do(request, EndpointAccount, "GetQuotaStatus")
+	if err != nil {
+		return nil, fmt.Errorf("GetQuotaStatus: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GetQuotaStatus: %w", NewUnexpectedStatusCodeError(response))
+	}
+	var status QuotaStatus
+	err = its.decode(response, format, &status)
+	if err != nil {
+		return nil, fmt.Errorf("GetQuotaStatus: %w", err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,26 @@
+package itscope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// CreateQuotation requests a quotation for the given products and quantities
+// and returns it with ITScope's pricing.
+func (its *ITScopeCommunicator) CreateQuotation(ctx context.Context, items []QuoteItem) (*Quotation, error) {
+	if len(items) == 0 {
+		return nil, errors.New("could not create quotation: no items")
+	}
+
+	var quotation Quotation
+	err := its.sendJSON(ctx, http.MethodPost, its.buildURL("quotations/quotation.json", nil), EndpointQuotations, "CreateQuotation", struct {
+		Items []QuoteItem `json:"items"`
+	}{Items: items}, &quotation)
+	if err != nil {
+		return nil, fmt.Errorf("could not create quotation: %w", err)
+	}
+
+	return &quotation, nil
+}
@@ -0,0 +1,58 @@
+package itscope
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// GetReferenceData returns the currency, country and unit codes used in
+// product data with their names in the request language. The result is kept
+// for the TTL set with WithReferenceDataTTL and must be treated as read-only.
+func (its *ITScopeCommunicator) GetReferenceData(ctx context.Context) (*ReferenceData, error) {
+	language := its.languageFor(ctx)
+	its.references.mu.Lock()
+	entry, ok := its.references.entries[language]
+	its.references.mu.Unlock()
+	if ok && its.clock.Now().Sub(entry.fetchedAt) < its.references.ttl {
+		its.stats.cacheHits.Add(1)
+		return entry.data, nil
+	}
+	its.stats.cacheMisses.Add(1)
+
+	data, err := its.fetchReferenceData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetReferenceData: %w", err)
+	}
+
+	its.references.mu.Lock()
+	its.references.entries[language] = referenceDataCacheEntry{data: data, fetchedAt: its.clock.Now()}
+	its.references.mu.Unlock()
+
+	return data, nil
+}
+
+func (its *ITScopeCommunicator) fetchReferenceData(ctx context.Context) (*ReferenceData, error) {
+	format := its.format
+	request, err := its.newRequest(ctx, http.MethodGet, its.buildURL("reference/referencedata."+string(format), nil), nil, format)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := its.do(request, EndpointReference, "GetReferenceData")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, NewUnexpectedStatusCodeError(response)
+	}
+	var data ReferenceData
+	err = its.decode(response, format, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
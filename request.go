@@ -0,0 +1,79 @@
+package itscope
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const apiBaseURL = "https://api.itscope.com/"
+
+// buildURL appends path, which must already be escaped, to the API base URL
+// of the configured API version and adds values sorted by key, so equivalent
+// requests share one URL.
+func (its *ITScopeCommunicator) buildURL(path string, values url.Values) string {
+	urlString := apiBaseURL + its.apiVersion + "/" + path
+	if len(values) > 0 {
+		urlString += "?" + values.Encode()
+	}
+	return urlString
+}
+
+// newRequest builds an authenticated request for format. A body is buffered,
+// so retries can resend it, and sent with the media type of format.
+func (its *ITScopeCommunicator) newRequest(ctx context.Context, method string, urlString string, body io.Reader, format Format) (*http.Request, error) {
+	var buffered io.Reader
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		buffered = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, urlString, buffered)
+	if err != nil {
+		return nil, err
+	}
+	err = its.authenticateRequest(request, format)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", format.mediaType())
+	}
+
+	return request, nil
+}
+
+// sendJSON sends in as JSON body and decodes a successful response into out.
+// Any status other than 200 and 201 is returned as UnexpectedStatusCodeError.
+func (its *ITScopeCommunicator) sendJSON(ctx context.Context, method string, urlString string, endpoint Endpoint, operation string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	request, err := its.newRequest(ctx, method, urlString, bytes.NewReader(body), FormatJSON)
+	if err != nil {
+		return err
+	}
+
+	response, err := its.do(request, endpoint, operation)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return NewUnexpectedStatusCodeError(response)
+	}
+	if out == nil {
+		return nil
+	}
+
+	return its.decode(response, FormatJSON, out)
+}
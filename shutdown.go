@@ -0,0 +1,84 @@
+package itscope
+
+import (
+	"context"
+	"sync"
+)
+
+// operationTracker counts the operations in flight for Shutdown.
+type operationTracker struct {
+	mu     sync.Mutex
+	active int
+	closed bool
+	idle   chan struct{}
+}
+
+// beginOperation registers an operation Shutdown waits for. Requests made
+// with the returned context belong to the operation and are accepted until it
+// ends, even while shutting down. After Shutdown or Close, new operations
+// fail with ErrShutdown.
+func (its *ITScopeCommunicator) beginOperation(ctx context.Context) (context.Context, func(), error) {
+	if _, ok := ctx.Value(operationKey).(bool); ok {
+		return ctx, func() {}, nil
+	}
+
+	tracker := &its.operations
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if tracker.closed {
+		return nil, nil, ErrShutdown
+	}
+	tracker.active++
+
+	var once sync.Once
+	return context.WithValue(ctx, operationKey, true), func() { once.Do(tracker.end) }, nil
+}
+
+func (t *operationTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	if t.active == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// Shutdown stops accepting new operations and waits until the requests and
+// batch operations in flight have finished or ctx is done, then releases idle
+// connections. It returns right away if nothing is in flight.
+func (its *ITScopeCommunicator) Shutdown(ctx context.Context) error {
+	tracker := &its.operations
+	tracker.mu.Lock()
+	tracker.closed = true
+	if tracker.active == 0 {
+		tracker.mu.Unlock()
+		its.client.CloseIdleConnections()
+		return nil
+	}
+	if tracker.idle == nil {
+		tracker.idle = make(chan struct{})
+	}
+	idle := tracker.idle
+	tracker.mu.Unlock()
+
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	its.client.CloseIdleConnections()
+
+	return nil
+}
+
+// Close stops accepting new operations and releases idle connections without
+// waiting for operations in flight.
+func (its *ITScopeCommunicator) Close() error {
+	its.operations.mu.Lock()
+	its.operations.closed = true
+	its.operations.mu.Unlock()
+	its.client.CloseIdleConnections()
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package itscope
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Stats is a snapshot of the request counters of a communicator.
+type Stats struct {
+	Requests        int64
+	Retries         int64
+	ClientErrors    int64
+	ServerErrors    int64
+	TransportErrors int64
+	CacheHits       int64
+	CacheMisses     int64
+	BytesRead       int64
+}
+
+type stats struct {
+	requests        atomic.Int64
+	retries         atomic.Int64
+	clientErrors    atomic.Int64
+	serverErrors    atomic.Int64
+	transportErrors atomic.Int64
+	cacheHits       atomic.Int64
+	cacheMisses     atomic.Int64
+	bytesRead       atomic.Int64
+}
+
+func (its *ITScopeCommunicator) Stats() Stats {
+	return Stats{
+		Requests:        its.stats.requests.Load(),
+		Retries:         its.stats.retries.Load(),
+		ClientErrors:    its.stats.clientErrors.Load(),
+		ServerErrors:    its.stats.serverErrors.Load(),
+		TransportErrors: its.stats.transportErrors.Load(),
+		CacheHits:       its.stats.cacheHits.Load(),
+		CacheMisses:     its.stats.cacheMisses.Load(),
+		BytesRead:       its.stats.bytesRead.Load(),
+	}
+}
+
+func (its *ITScopeCommunicator) ResetStats() {
+	its.stats.requests.Store(0)
+	its.stats.retries.Store(0)
+	its.stats.clientErrors.Store(0)
+	its.stats.serverErrors.Store(0)
+	its.stats.transportErrors.Store(0)
+	its.stats.cacheHits.Store(0)
+	its.stats.cacheMisses.Store(0)
+	its.stats.bytesRead.Store(0)
+}
+
+func (s *stats) recordResponse(statusCode int, err error) {
+	s.requests.Add(1)
+	switch {
+	case err != nil:
+		s.transportErrors.Add(1)
+	case statusCode >= 500:
+		s.serverErrors.Add(1)
+	case statusCode >= 400 && statusCode != 404:
+		s.clientErrors.Add(1)
+	}
+}
+
+// countingBody counts the bytes read from a response body.
+type countingBody struct {
+	io.ReadCloser
+	counter *atomic.Int64
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.counter.Add(int64(n))
+	return n, err
+}
@@ -1,10 +1,13 @@
 package itscope
 
-import "sync"
+import (
+	"encoding/json"
+	"sync"
+)
 
 type ProductTypesContainer struct {
-	Mute         sync.Mutex
-	ProductTypes []ProductType `json:"productType"`
+	Mute         sync.Mutex    `json:"-" xml:"-"`
+	ProductTypes []ProductType `json:"productType" xml:"productType"`
 }
 
 type Language string
@@ -14,229 +17,449 @@ const (
 	English Language = "en"
 )
 
+type Endpoint string
+
+const (
+	EndpointSearch       Endpoint = "search"
+	EndpointProductTypes Endpoint = "producttypes"
+	EndpointRealtime     Endpoint = "realtime"
+	EndpointQuotations   Endpoint = "quotations"
+	EndpointFavorites    Endpoint = "favorites"
+	EndpointAccount      Endpoint = "account"
+	EndpointReference    Endpoint = "reference"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+	FormatCSV  Format = "csv"
+)
+
+type ProductTypeRepresentation string
+
+const (
+	ProductTypeReprStandard ProductTypeRepresentation = "producttype"
+	ProductTypeReprDetailed ProductTypeRepresentation = "producttypedetail"
+)
+
 type ProductType struct {
-	ID                 string           `json:"id"`
-	ProductTypeGroup   ProductTypeGroup `json:"productTypeGroup"`
-	Name               string           `json:"name"`
-	AttributeTypeId1   string           `json:"attributeTypeId1"`
-	AttributeTypeName1 string           `json:"attributeTypeName1"`
-	AttributeTypeId2   string           `json:"attributeTypeId2"`
-	AttributeTypeName2 string           `json:"attributeTypeName2"`
-	AttributeTypeId3   string           `json:"attributeTypeId3"`
-	AttributeTypeName3 string           `json:"attributeTypeName3"`
-	AttributeTypeId4   string           `json:"attributeTypeId4"`
-	AttributeTypeName4 string           `json:"attributeTypeName4"`
-	AttributeTypeId5   string           `json:"attributeTypeId5"`
-	AttributeTypeName5 string           `json:"attributeTypeName5"`
-	AttributeType      []AttributeType  `json:"attributeType"`
+	ID               string           `json:"id" xml:"id"`
+	ProductTypeGroup ProductTypeGroup `json:"productTypeGroup" xml:"productTypeGroup"`
+	Name             string           `json:"name" xml:"name"`
+	Description      string           `json:"description" xml:"description"`
+	ParentID         string           `json:"parentId" xml:"parentId"`
+	Icon             string           `json:"icon" xml:"icon"`
+	// Active is false for deprecated or hidden product types, which are only
+	// returned with ProductTypeIncludeInactive.
+	Active             bool            `json:"active" xml:"active"`
+	AttributeTypeId1   string          `json:"attributeTypeId1" xml:"attributeTypeId1"`
+	AttributeTypeName1 string          `json:"attributeTypeName1" xml:"attributeTypeName1"`
+	AttributeTypeId2   string          `json:"attributeTypeId2" xml:"attributeTypeId2"`
+	AttributeTypeName2 string          `json:"attributeTypeName2" xml:"attributeTypeName2"`
+	AttributeTypeId3   string          `json:"attributeTypeId3" xml:"attributeTypeId3"`
+	AttributeTypeName3 string          `json:"attributeTypeName3" xml:"attributeTypeName3"`
+	AttributeTypeId4   string          `json:"attributeTypeId4" xml:"attributeTypeId4"`
+	AttributeTypeName4 string          `json:"attributeTypeName4" xml:"attributeTypeName4"`
+	AttributeTypeId5   string          `json:"attributeTypeId5" xml:"attributeTypeId5"`
+	AttributeTypeName5 string          `json:"attributeTypeName5" xml:"attributeTypeName5"`
+	AttributeType      []AttributeType `json:"attributeType" xml:"attributeType"`
+}
+
+type ProductTypeNode struct {
+	ProductType ProductType
+	Children    []ProductTypeNode
+}
+
+type GroupOverview struct {
+	Group ProductTypeGroup
+	Types []ProductTypeOverview
+}
+
+type ProductTypeOverview struct {
+	ProductType  ProductType
+	ProductCount int
 }
 
 type AttributeType struct {
-	ID            string        `json:"id"`
-	Name          string        `json:"name"`
-	Rank          string        `json:"rank"`
-	Type          string        `json:"type"`
-	AttributeUnit AttributeUnit `json:"attributeUnit"`
-	GroupID       string        `json:"groupId"`
-	GroupName     string        `json:"groupName"`
+	ID            string        `json:"id" xml:"id"`
+	Name          string        `json:"name" xml:"name"`
+	Rank          string        `json:"rank" xml:"rank"`
+	Type          string        `json:"type" xml:"type"`
+	AttributeUnit AttributeUnit `json:"attributeUnit" xml:"attributeUnit"`
+	GroupID       string        `json:"groupId" xml:"groupId"`
+	GroupName     string        `json:"groupName" xml:"groupName"`
 }
 
 type AttributeUnit struct {
-	ID         string `json:"id"`
-	BaseUnitID string `json:"baseUnitId"`
-	MulFactor  int64  `json:"mulFactor"`
-	DivFactor  int64  `json:"divFactor"`
+	ID         string `json:"id" xml:"id"`
+	BaseUnitID string `json:"baseUnitId" xml:"baseUnitId"`
+	MulFactor  int64  `json:"mulFactor" xml:"mulFactor"`
+	DivFactor  int64  `json:"divFactor" xml:"divFactor"`
 }
 
 type ProductTypeGroup struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID   string `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
 }
 
+// Known product-type group IDs.
+const (
+	ProductTypeGroupService = "SSP"
+)
+
+type PriceType string
+
+const (
+	PriceTypeNet             PriceType = "NET"
+	PriceTypeNetWithShipping PriceType = "NET_SHIPPING"
+	PriceTypeList            PriceType = "LIST"
+)
+
+type Sort string
+
+const (
+	SortDefault   Sort = "DEFAULT"
+	SortRelevance Sort = "RELEVANCE"
+)
+
+type Representation string
+
+const (
+	RepresentationStandard Representation = "standard"
+	RepresentationDetail   Representation = "detail"
+)
+
 // Generated by https://quicktype.io
 
 type ProductsContainer struct {
-	Product []Product `json:"product"`
+	Product           []Product `json:"product" xml:"product"`
+	TotalProductCount int       `json:"totalProductCount" xml:"totalProductCount"`
+	// InterpretedQuery is the query as ITScope parsed it, useful to spot
+	// syntax mistakes by comparing it to the query sent.
+	InterpretedQuery string `json:"interpretedQuery" xml:"interpretedQuery"`
+	// BaseCurrency is the currency the prices are quoted in and
+	// ExchangeRates the rates ITScope used to convert into it, keyed by
+	// currency code. Both stay empty if the response does not carry them.
+	BaseCurrency  string             `json:"baseCurrency" xml:"baseCurrency"`
+	ExchangeRates map[string]float64 `json:"exchangeRates" xml:"-"`
+	// Truncated is set by ITScope if more products match than it returns
+	// for a query; TotalProductCount is then capped as well. Narrow the
+	// query to see all matches.
+	Truncated bool `json:"truncated" xml:"truncated"`
+
+	// Stale is set on results served from an expired cache entry because
+	// the request failed, see WithServeStaleOnError.
+	Stale bool `json:"-" xml:"-"`
 }
 
 type Product struct {
-	Puid                        string             `json:"puid"`
-	Ean                         string             `json:"ean"`
-	ManufacturerSKU             string             `json:"manufacturerSKU"`
-	IcecatID                    string             `json:"icecatId"`
-	CnetID                      string             `json:"cnetId"`
-	BechlemID                   string             `json:"bechlemId"`
-	EClass                      string             `json:"eClass"`
-	ManufacturerID              string             `json:"manufacturerId"`
-	ManufacturerName            string             `json:"manufacturerName"`
-	ProductNameWithManufacturer string             `json:"productNameWithManufacturer"`
-	ShortDescription            string             `json:"shortDescription"`
-	LongDescription             string             `json:"longDescription"`
-	ProductTypeID               string             `json:"productTypeId"`
-	ProductTypeGroupID          string             `json:"productTypeGroupId"`
-	ProductTypeGroupName        string             `json:"productTypeGroupName"`
-	ProductTypeName             string             `json:"productTypeName"`
-	AttributeTypeId1            string             `json:"attributeTypeId1"`
-	AttributeTypeName1          string             `json:"attributeTypeName1"`
-	AttributeTypeId2            string             `json:"attributeTypeId2"`
-	AttributeTypeName2          string             `json:"attributeTypeName2"`
-	AttributeTypeId3            string             `json:"attributeTypeId3"`
-	AttributeTypeName3          string             `json:"attributeTypeName3"`
-	AttributeTypeId4            string             `json:"attributeTypeId4"`
-	AttributeTypeName4          string             `json:"attributeTypeName4"`
-	AttributeTypeId5            string             `json:"attributeTypeId5"`
-	AttributeTypeName5          string             `json:"attributeTypeName5"`
-	AttributeValue1             string             `json:"attributeValue1"`
-	AttributeValue2             string             `json:"attributeValue2"`
-	AttributeValue3             string             `json:"attributeValue3"`
-	AttributeValue4             string             `json:"attributeValue4"`
-	AttributeValue5             string             `json:"attributeValue5"`
-	ProductSubTypeID            string             `json:"productSubTypeId"`
-	ProductSubType              string             `json:"productSubType"`
-	ProductLineID               string             `json:"productLineId"`
-	ProductLine                 string             `json:"productLine"`
-	ProductModel                string             `json:"productModel"`
-	EstimateGrossWeight         string             `json:"estimateGrossWeight"`
-	GrossDimX                   string             `json:"grossDimX"`
-	GrossDimY                   string             `json:"grossDimY"`
-	GrossDimZ                   string             `json:"grossDimZ"`
-	CustomsTariffNumber         string             `json:"customsTariffNumber"`
-	Deeplink                    string             `json:"deeplink"`
-	StandardHTMLDatasheet       string             `json:"standardHtmlDatasheet"`
-	StandardPDFDatasheet        string             `json:"standardPdfDatasheet"`
-	ManufacturerSite            string             `json:"manufacturerSite"`
-	ManufacturerDatasheet       string             `json:"manufacturerDatasheet"`
-	ImageThumb                  string             `json:"imageThumb"`
-	ImageThumbWidth             string             `json:"imageThumbWidth"`
-	ImageThumbHeight            string             `json:"imageThumbHeight"`
-	Image1                      string             `json:"image1"`
-	ImageWidth1                 string             `json:"imageWidth1"`
-	ImageHeight1                string             `json:"imageHeight1"`
-	Image2                      string             `json:"image2"`
-	ImageWidth2                 string             `json:"imageWidth2"`
-	ImageHeight2                string             `json:"imageHeight2"`
-	Image3                      string             `json:"image3"`
-	ImageWidth3                 string             `json:"imageWidth3"`
-	ImageHeight3                string             `json:"imageHeight3"`
-	Image4                      string             `json:"image4"`
-	ImageWidth4                 string             `json:"imageWidth4"`
-	ImageHeight4                string             `json:"imageHeight4"`
-	Image5                      string             `json:"image5"`
-	ImageWidth5                 string             `json:"imageWidth5"`
-	ImageHeight5                string             `json:"imageHeight5"`
-	EnergyLabel                 string             `json:"energyLabel"`
-	EntryDate                   string             `json:"entryDate"`
-	Rank                        string             `json:"rank"`
-	Qualification               string             `json:"qualification"`
-	WarrantyText                string             `json:"warrantyText"`
-	MarketingText               string             `json:"marketingText"`
-	HTMLSpecs                   string             `json:"htmlSpecs"`
-	RecommendedRetailPriceNet   string             `json:"recommendedRetailPriceNet"`
-	Price                       string             `json:"price"`
-	PriceCalc                   string             `json:"priceCalc"`
-	CurrencyCode                string             `json:"currencyCode"`
-	PriceCalcVat                string             `json:"priceCalcVat"`
-	PriceLastUpdate             string             `json:"priceLastUpdate"`
-	PriceSupplierID             string             `json:"priceSupplierId"`
-	PriceSupplierName           string             `json:"priceSupplierName"`
-	PriceSupplierItemID         string             `json:"priceSupplierItemId"`
-	PriceSupplierSKU            string             `json:"priceSupplierSKU"`
-	StockSupplierText           string             `json:"stockSupplierText"`
-	StockStatus                 string             `json:"stockStatus"`
-	StockStatusText             string             `json:"stockStatusText"`
-	Stock                       string             `json:"stock"`
-	ExternalStock               string             `json:"externalStock"`
-	IncomingStock               string             `json:"incomingStock"`
-	StockAvailabilityDate       string             `json:"stockAvailabilityDate"`
-	StockLastUpdate             string             `json:"stockLastUpdate"`
-	AggregatedStatus            string             `json:"aggregatedStatus"`
-	AggregatedStatusText        string             `json:"aggregatedStatusText"`
-	AggregatedStock             string             `json:"aggregatedStock"`
-	AggregatedSupplierItems     string             `json:"aggregatedSupplierItems"`
-	SupplierItems               []SupplierItem     `json:"supplierItems"`
-	Attributes                  []Attribute        `json:"attributes"`
-	AttributeClusters           []AttributeCluster `json:"attributeClusters"`
-	Accessories                 []Accessory        `json:"accessories"`
+	Puid                        string             `json:"puid" xml:"puid"`
+	Ean                         string             `json:"ean" xml:"ean"`
+	ManufacturerSKU             string             `json:"manufacturerSKU" xml:"manufacturerSKU"`
+	IcecatID                    string             `json:"icecatId" xml:"icecatId"`
+	CnetID                      string             `json:"cnetId" xml:"cnetId"`
+	BechlemID                   string             `json:"bechlemId" xml:"bechlemId"`
+	EClass                      string             `json:"eClass" xml:"eClass"`
+	EtimClass                   string             `json:"etimClass" xml:"etimClass"`
+	Unspsc                      string             `json:"unspsc" xml:"unspsc"`
+	ManufacturerID              string             `json:"manufacturerId" xml:"manufacturerId"`
+	ManufacturerName            string             `json:"manufacturerName" xml:"manufacturerName"`
+	ProductNameWithManufacturer string             `json:"productNameWithManufacturer" xml:"productNameWithManufacturer"`
+	ShortDescription            string             `json:"shortDescription" xml:"shortDescription"`
+	LongDescription             string             `json:"longDescription" xml:"longDescription"`
+	ProductTypeID               string             `json:"productTypeId" xml:"productTypeId"`
+	ProductTypeGroupID          string             `json:"productTypeGroupId" xml:"productTypeGroupId"`
+	ProductTypeGroupName        string             `json:"productTypeGroupName" xml:"productTypeGroupName"`
+	ProductTypeName             string             `json:"productTypeName" xml:"productTypeName"`
+	AttributeTypeId1            string             `json:"attributeTypeId1" xml:"attributeTypeId1"`
+	AttributeTypeName1          string             `json:"attributeTypeName1" xml:"attributeTypeName1"`
+	AttributeTypeId2            string             `json:"attributeTypeId2" xml:"attributeTypeId2"`
+	AttributeTypeName2          string             `json:"attributeTypeName2" xml:"attributeTypeName2"`
+	AttributeTypeId3            string             `json:"attributeTypeId3" xml:"attributeTypeId3"`
+	AttributeTypeName3          string             `json:"attributeTypeName3" xml:"attributeTypeName3"`
+	AttributeTypeId4            string             `json:"attributeTypeId4" xml:"attributeTypeId4"`
+	AttributeTypeName4          string             `json:"attributeTypeName4" xml:"attributeTypeName4"`
+	AttributeTypeId5            string             `json:"attributeTypeId5" xml:"attributeTypeId5"`
+	AttributeTypeName5          string             `json:"attributeTypeName5" xml:"attributeTypeName5"`
+	AttributeValue1             string             `json:"attributeValue1" xml:"attributeValue1"`
+	AttributeValue2             string             `json:"attributeValue2" xml:"attributeValue2"`
+	AttributeValue3             string             `json:"attributeValue3" xml:"attributeValue3"`
+	AttributeValue4             string             `json:"attributeValue4" xml:"attributeValue4"`
+	AttributeValue5             string             `json:"attributeValue5" xml:"attributeValue5"`
+	ProductSubTypeID            string             `json:"productSubTypeId" xml:"productSubTypeId"`
+	ProductSubType              string             `json:"productSubType" xml:"productSubType"`
+	ProductLineID               string             `json:"productLineId" xml:"productLineId"`
+	ProductLine                 string             `json:"productLine" xml:"productLine"`
+	ProductModel                string             `json:"productModel" xml:"productModel"`
+	EstimateGrossWeight         string             `json:"estimateGrossWeight" xml:"estimateGrossWeight"`
+	GrossDimX                   string             `json:"grossDimX" xml:"grossDimX"`
+	GrossDimY                   string             `json:"grossDimY" xml:"grossDimY"`
+	GrossDimZ                   string             `json:"grossDimZ" xml:"grossDimZ"`
+	CustomsTariffNumber         string             `json:"customsTariffNumber" xml:"customsTariffNumber"`
+	Deeplink                    string             `json:"deeplink" xml:"deeplink"`
+	StandardHTMLDatasheet       string             `json:"standardHtmlDatasheet" xml:"standardHtmlDatasheet"`
+	StandardPDFDatasheet        string             `json:"standardPdfDatasheet" xml:"standardPdfDatasheet"`
+	ManufacturerSite            string             `json:"manufacturerSite" xml:"manufacturerSite"`
+	ManufacturerDatasheet       string             `json:"manufacturerDatasheet" xml:"manufacturerDatasheet"`
+	ImageThumb                  string             `json:"imageThumb" xml:"imageThumb"`
+	ImageThumbWidth             string             `json:"imageThumbWidth" xml:"imageThumbWidth"`
+	ImageThumbHeight            string             `json:"imageThumbHeight" xml:"imageThumbHeight"`
+	Image1                      string             `json:"image1" xml:"image1"`
+	ImageWidth1                 string             `json:"imageWidth1" xml:"imageWidth1"`
+	ImageHeight1                string             `json:"imageHeight1" xml:"imageHeight1"`
+	ImageHighRes1               string             `json:"imageHighRes1" xml:"imageHighRes1"`
+	Image2                      string             `json:"image2" xml:"image2"`
+	ImageWidth2                 string             `json:"imageWidth2" xml:"imageWidth2"`
+	ImageHeight2                string             `json:"imageHeight2" xml:"imageHeight2"`
+	ImageHighRes2               string             `json:"imageHighRes2" xml:"imageHighRes2"`
+	Image3                      string             `json:"image3" xml:"image3"`
+	ImageWidth3                 string             `json:"imageWidth3" xml:"imageWidth3"`
+	ImageHeight3                string             `json:"imageHeight3" xml:"imageHeight3"`
+	ImageHighRes3               string             `json:"imageHighRes3" xml:"imageHighRes3"`
+	Image4                      string             `json:"image4" xml:"image4"`
+	ImageWidth4                 string             `json:"imageWidth4" xml:"imageWidth4"`
+	ImageHeight4                string             `json:"imageHeight4" xml:"imageHeight4"`
+	ImageHighRes4               string             `json:"imageHighRes4" xml:"imageHighRes4"`
+	Image5                      string             `json:"image5" xml:"image5"`
+	ImageWidth5                 string             `json:"imageWidth5" xml:"imageWidth5"`
+	ImageHeight5                string             `json:"imageHeight5" xml:"imageHeight5"`
+	ImageHighRes5               string             `json:"imageHighRes5" xml:"imageHighRes5"`
+	EnergyLabel                 string             `json:"energyLabel" xml:"energyLabel"`
+	EntryDate                   string             `json:"entryDate" xml:"entryDate"`
+	LastModified                string             `json:"lastModified" xml:"lastModified"`
+	Rank                        string             `json:"rank" xml:"rank"`
+	RatingAverage               string             `json:"ratingAverage" xml:"ratingAverage"`
+	RatingCount                 string             `json:"ratingCount" xml:"ratingCount"`
+	Qualification               string             `json:"qualification" xml:"qualification"`
+	WarrantyText                string             `json:"warrantyText" xml:"warrantyText"`
+	ServiceDuration             string             `json:"serviceDuration" xml:"serviceDuration"`
+	ServiceCoverage             string             `json:"serviceCoverage" xml:"serviceCoverage"`
+	MarketingText               string             `json:"marketingText" xml:"marketingText"`
+	FeatureBullets              []string           `json:"featureBullets" xml:"featureBullets"`
+	HTMLSpecs                   string             `json:"htmlSpecs" xml:"htmlSpecs"`
+	RecommendedRetailPriceNet   string             `json:"recommendedRetailPriceNet" xml:"recommendedRetailPriceNet"`
+	Price                       string             `json:"price" xml:"price"`
+	PriceCalc                   string             `json:"priceCalc" xml:"priceCalc"`
+	CurrencyCode                string             `json:"currencyCode" xml:"currencyCode"`
+	PriceCalcVat                string             `json:"priceCalcVat" xml:"priceCalcVat"`
+	PriceType                   string             `json:"priceType" xml:"priceType"`
+	DealPrice                   string             `json:"dealPrice" xml:"dealPrice"`
+	DealValidFrom               string             `json:"dealValidFrom" xml:"dealValidFrom"`
+	DealValidTo                 string             `json:"dealValidTo" xml:"dealValidTo"`
+	PriceLastUpdate             string             `json:"priceLastUpdate" xml:"priceLastUpdate"`
+	PriceSupplierID             string             `json:"priceSupplierId" xml:"priceSupplierId"`
+	PriceSupplierName           string             `json:"priceSupplierName" xml:"priceSupplierName"`
+	PriceSupplierItemID         string             `json:"priceSupplierItemId" xml:"priceSupplierItemId"`
+	PriceSupplierSKU            string             `json:"priceSupplierSKU" xml:"priceSupplierSKU"`
+	StockSupplierText           string             `json:"stockSupplierText" xml:"stockSupplierText"`
+	StockStatus                 string             `json:"stockStatus" xml:"stockStatus"`
+	StockStatusText             string             `json:"stockStatusText" xml:"stockStatusText"`
+	Stock                       string             `json:"stock" xml:"stock"`
+	ExternalStock               string             `json:"externalStock" xml:"externalStock"`
+	IncomingStock               string             `json:"incomingStock" xml:"incomingStock"`
+	StockAvailabilityDate       string             `json:"stockAvailabilityDate" xml:"stockAvailabilityDate"`
+	StockLastUpdate             string             `json:"stockLastUpdate" xml:"stockLastUpdate"`
+	AggregatedStatus            string             `json:"aggregatedStatus" xml:"aggregatedStatus"`
+	AggregatedStatusText        string             `json:"aggregatedStatusText" xml:"aggregatedStatusText"`
+	AggregatedStock             string             `json:"aggregatedStock" xml:"aggregatedStock"`
+	AggregatedSupplierItems     string             `json:"aggregatedSupplierItems" xml:"aggregatedSupplierItems"`
+	SupplierItems               []SupplierItem     `json:"supplierItems" xml:"supplierItems"`
+	Attributes                  []Attribute        `json:"attributes" xml:"attributes"`
+	AttributeClusters           []AttributeCluster `json:"attributeClusters" xml:"attributeClusters"`
+	Accessories                 []Accessory        `json:"accessories" xml:"accessories"`
+	BundleComponents            []BundleComponent  `json:"bundleComponents" xml:"bundleComponents"`
+
+	// ResolvedAccessories holds the full products behind Accessories once
+	// attached with AttachAccessories.
+	ResolvedAccessories []Product `json:"resolvedAccessories,omitempty" xml:"-"`
+
+	// Extra holds the JSON fields of the product that are not mapped to a
+	// struct field above. Typed fields always take precedence.
+	Extra map[string]json.RawMessage `json:"-" xml:"-"`
+}
+
+type BundleComponent struct {
+	ReferencedProductID string `json:"referencedProductId" xml:"referencedProductId"`
+	Quantity            int64  `json:"quantity" xml:"quantity"`
+}
+
+type ImageRole string
+
+const (
+	ImageRoleThumbnail ImageRole = "thumbnail"
+	ImageRoleStandard  ImageRole = "standard"
+	ImageRoleHighRes   ImageRole = "highres"
+)
+
+type ProductImage struct {
+	URL    string
+	Role   ImageRole
+	Width  int
+	Height int
+}
+
+type ClassificationScheme string
+
+const (
+	ClassificationECLASS ClassificationScheme = "eclass"
+	ClassificationETIM   ClassificationScheme = "etim"
+	ClassificationUNSPSC ClassificationScheme = "unspsc"
+)
+
+type Classification struct {
+	Scheme ClassificationScheme
+	Code   string
+}
+
+// ProductWithType is a product joined with its product type (and thereby
+// its group). Type is nil if the product type is unknown.
+type ProductWithType struct {
+	Product
+	Type *ProductType `json:"type"`
 }
 
 type Accessory struct {
-	ReferencedProductID string `json:"referencedProductId"`
-	TypeID              string `json:"typeId"`
-	Type                string `json:"type"`
+	ReferencedProductID string `json:"referencedProductId" xml:"referencedProductId"`
+	TypeID              string `json:"typeId" xml:"typeId"`
+	Type                string `json:"type" xml:"type"`
 }
 
 type Attribute struct {
-	DisplayValue           string `json:"displayValue"`
-	AttributeTypeID        int64  `json:"attributeTypeId"`
-	AttributeTypeName      string `json:"attributeTypeName"`
-	AttributeTypeRank      int64  `json:"attributeTypeRank"`
-	AttributeTypeGroupID   int64  `json:"attributeTypeGroupId"`
-	AttributeTypeGroupName string `json:"attributeTypeGroupName"`
+	DisplayValue           string `json:"displayValue" xml:"displayValue"`
+	AttributeTypeID        int64  `json:"attributeTypeId" xml:"attributeTypeId"`
+	AttributeTypeName      string `json:"attributeTypeName" xml:"attributeTypeName"`
+	AttributeTypeRank      int64  `json:"attributeTypeRank" xml:"attributeTypeRank"`
+	AttributeTypeGroupID   int64  `json:"attributeTypeGroupId" xml:"attributeTypeGroupId"`
+	AttributeTypeGroupName string `json:"attributeTypeGroupName" xml:"attributeTypeGroupName"`
 }
 
 type AttributeCluster struct {
-	ID                     int64  `json:"id"`
-	ProductTypeID          int64  `json:"productTypeId"`
-	ProductTypeName        string `json:"productTypeName"`
-	AttributeTypeName      string `json:"attributeTypeName"`
-	AttributeTypeGroupName string `json:"attributeTypeGroupName"`
-	Name                   string `json:"name"`
-	Rank                   int64  `json:"rank"`
+	ID                     int64  `json:"id" xml:"id"`
+	ProductTypeID          int64  `json:"productTypeId" xml:"productTypeId"`
+	ProductTypeName        string `json:"productTypeName" xml:"productTypeName"`
+	AttributeTypeName      string `json:"attributeTypeName" xml:"attributeTypeName"`
+	AttributeTypeGroupName string `json:"attributeTypeGroupName" xml:"attributeTypeGroupName"`
+	Name                   string `json:"name" xml:"name"`
+	Rank                   int64  `json:"rank" xml:"rank"`
 }
 
 type SupplierItem struct {
-	ID                        string    `json:"id"`
-	Ean                       string    `json:"ean"`
-	ManufacturerSKU           string    `json:"manufacturerSKU"`
-	SupplierSKU               string    `json:"supplierSKU"`
-	SupplierID                string    `json:"supplierId"`
-	SupplierName              string    `json:"supplierName"`
-	ManufacturerName          string    `json:"manufacturerName"`
-	ProductName               string    `json:"productName"`
-	LongDescription           string    `json:"longDescription"`
-	ConditionID               string    `json:"conditionId"`
-	ConditionName             string    `json:"conditionName"`
-	EOLProduct                string    `json:"eolProduct"`
-	MatchQuality              string    `json:"matchQuality"`
-	EanValid                  string    `json:"eanValid"`
-	SpecialOffer              string    `json:"specialOffer"`
-	Promotion                 string    `json:"promotion"`
-	Vat                       string    `json:"vat"`
-	CopyrightLevy             string    `json:"copyrightLevy"`
-	CustomsTariffNumber       string    `json:"customsTariffNumber"`
-	CountryOfOrigin           string    `json:"countryOfOrigin"`
-	GrossDimX                 string    `json:"grossDimX"`
-	GrossDimY                 string    `json:"grossDimY"`
-	GrossDimZ                 string    `json:"grossDimZ"`
-	WarrantyText              string    `json:"warrantyText"`
-	Deeplink                  string    `json:"deeplink"`
-	RecommendedRetailPriceNet string    `json:"recommendedRetailPriceNet"`
-	Price                     string    `json:"price"`
-	PriceCalc                 string    `json:"priceCalc"`
-	CurrencyCode              string    `json:"currencyCode"`
-	PriceCalcVat              string    `json:"priceCalcVat"`
-	PriceLastUpdate           string    `json:"priceLastUpdate"`
-	StockSupplierText         string    `json:"stockSupplierText"`
-	StockStatus               string    `json:"stockStatus"`
-	StockStatusText           string    `json:"stockStatusText"`
-	Stock                     string    `json:"stock"`
-	ExternalStock             string    `json:"externalStock"`
-	IncomingStock             string    `json:"incomingStock"`
-	StockAvailabilityDate     string    `json:"stockAvailabilityDate"`
-	LastStockUpdate           string    `json:"lastStockUpdate"`
-	Project                   []Project `json:"project"`
+	ID                        string    `json:"id" xml:"id"`
+	Ean                       string    `json:"ean" xml:"ean"`
+	ManufacturerSKU           string    `json:"manufacturerSKU" xml:"manufacturerSKU"`
+	SupplierSKU               string    `json:"supplierSKU" xml:"supplierSKU"`
+	SupplierID                string    `json:"supplierId" xml:"supplierId"`
+	SupplierName              string    `json:"supplierName" xml:"supplierName"`
+	ManufacturerName          string    `json:"manufacturerName" xml:"manufacturerName"`
+	ProductName               string    `json:"productName" xml:"productName"`
+	LongDescription           string    `json:"longDescription" xml:"longDescription"`
+	ConditionID               string    `json:"conditionId" xml:"conditionId"`
+	ConditionName             string    `json:"conditionName" xml:"conditionName"`
+	EOLProduct                string    `json:"eolProduct" xml:"eolProduct"`
+	MatchQuality              string    `json:"matchQuality" xml:"matchQuality"`
+	EanValid                  string    `json:"eanValid" xml:"eanValid"`
+	SpecialOffer              string    `json:"specialOffer" xml:"specialOffer"`
+	Promotion                 string    `json:"promotion" xml:"promotion"`
+	Vat                       string    `json:"vat" xml:"vat"`
+	CopyrightLevy             string    `json:"copyrightLevy" xml:"copyrightLevy"`
+	CustomsTariffNumber       string    `json:"customsTariffNumber" xml:"customsTariffNumber"`
+	CountryOfOrigin           string    `json:"countryOfOrigin" xml:"countryOfOrigin"`
+	GrossDimX                 string    `json:"grossDimX" xml:"grossDimX"`
+	GrossDimY                 string    `json:"grossDimY" xml:"grossDimY"`
+	GrossDimZ                 string    `json:"grossDimZ" xml:"grossDimZ"`
+	WarrantyText              string    `json:"warrantyText" xml:"warrantyText"`
+	Deeplink                  string    `json:"deeplink" xml:"deeplink"`
+	RecommendedRetailPriceNet string    `json:"recommendedRetailPriceNet" xml:"recommendedRetailPriceNet"`
+	Price                     string    `json:"price" xml:"price"`
+	PriceCalc                 string    `json:"priceCalc" xml:"priceCalc"`
+	CurrencyCode              string    `json:"currencyCode" xml:"currencyCode"`
+	PriceCalcVat              string    `json:"priceCalcVat" xml:"priceCalcVat"`
+	PriceType                 string    `json:"priceType" xml:"priceType"`
+	PriceLastUpdate           string    `json:"priceLastUpdate" xml:"priceLastUpdate"`
+	StockSupplierText         string    `json:"stockSupplierText" xml:"stockSupplierText"`
+	StockStatus               string    `json:"stockStatus" xml:"stockStatus"`
+	StockStatusText           string    `json:"stockStatusText" xml:"stockStatusText"`
+	Stock                     string    `json:"stock" xml:"stock"`
+	ExternalStock             string    `json:"externalStock" xml:"externalStock"`
+	IncomingStock             string    `json:"incomingStock" xml:"incomingStock"`
+	StockAvailabilityDate     string    `json:"stockAvailabilityDate" xml:"stockAvailabilityDate"`
+	LastStockUpdate           string    `json:"lastStockUpdate" xml:"lastStockUpdate"`
+	Project                   []Project `json:"project" xml:"project"`
+}
+
+type DistributorOffer struct {
+	SupplierID      string
+	SupplierName    string
+	SupplierSKU     string
+	Price           float64
+	CurrencyCode    string
+	Stock           int64
+	StockStatus     string
+	AvailableFrom   string
+	PriceLastUpdate string
+	StockLastUpdate string
+}
+
+type ReferenceData struct {
+	Currencies []ReferenceCode `json:"currencies" xml:"currencies"`
+	Countries  []ReferenceCode `json:"countries" xml:"countries"`
+	Units      []ReferenceCode `json:"units" xml:"units"`
+}
+
+type ReferenceCode struct {
+	Code string `json:"code" xml:"code"`
+	Name string `json:"name" xml:"name"`
+}
+
+type QuotaStatus struct {
+	Limit     int64  `json:"limit" xml:"limit"`
+	Used      int64  `json:"used" xml:"used"`
+	Remaining int64  `json:"remaining" xml:"remaining"`
+	ResetAt   string `json:"resetAt" xml:"resetAt"`
+}
+
+type FavoritesContainer struct {
+	Favorite []Favorite `json:"favorite" xml:"favorite"`
+}
+
+type Favorite struct {
+	Puid string `json:"puid" xml:"puid"`
+	Name string `json:"name" xml:"name"`
+}
+
+type QuoteItem struct {
+	Puid     string `json:"puid"`
+	Quantity int    `json:"quantity"`
+}
+
+type Quotation struct {
+	ID           string          `json:"id"`
+	Items        []QuotationItem `json:"items"`
+	TotalPrice   string          `json:"totalPrice"`
+	CurrencyCode string          `json:"currencyCode"`
+	ValidTo      string          `json:"validTo"`
+}
+
+type QuotationItem struct {
+	Puid         string `json:"puid"`
+	Quantity     int    `json:"quantity"`
+	Price        string `json:"price"`
+	SupplierID   string `json:"supplierId"`
+	SupplierName string `json:"supplierName"`
 }
 
 type Project struct {
-	SupplierProjectID     string `json:"supplierProjectId"`
-	ManufacturerProjectID string `json:"manufacturerProjectId"`
-	ProjectName           string `json:"projectName"`
-	EndCustomer           string `json:"endCustomer"`
-	Price                 int64  `json:"price"`
-	ValidFrom             string `json:"validFrom"`
-	ValidTo               string `json:"validTo"`
-	TargetQuantity        int64  `json:"targetQuantity"`
-	RemainingQuantity     int64  `json:"remainingQuantity"`
-	MinQuantity           int64  `json:"minQuantity"`
-	ProjectLastUpdate     string `json:"projectLastUpdate"`
+	SupplierProjectID     string `json:"supplierProjectId" xml:"supplierProjectId"`
+	ManufacturerProjectID string `json:"manufacturerProjectId" xml:"manufacturerProjectId"`
+	ProjectName           string `json:"projectName" xml:"projectName"`
+	EndCustomer           string `json:"endCustomer" xml:"endCustomer"`
+	Price                 int64  `json:"price" xml:"price"`
+	ValidFrom             string `json:"validFrom" xml:"validFrom"`
+	ValidTo               string `json:"validTo" xml:"validTo"`
+	TargetQuantity        int64  `json:"targetQuantity" xml:"targetQuantity"`
+	RemainingQuantity     int64  `json:"remainingQuantity" xml:"remainingQuantity"`
+	MinQuantity           int64  `json:"minQuantity" xml:"minQuantity"`
+	ProjectLastUpdate     string `json:"projectLastUpdate" xml:"projectLastUpdate"`
 }
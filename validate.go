@@ -0,0 +1,74 @@
+package itscope
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationRule checks a product and returns the problems it finds.
+type ValidationRule func(p *Product) []error
+
+// DefaultValidationRules are applied by Product.Validate: a Puid and a name
+// are required and a given price must be a non-negative number.
+var DefaultValidationRules = []ValidationRule{
+	RequireFields("puid", "productNameWithManufacturer"),
+	validatePrice,
+}
+
+// Validate checks the product against DefaultValidationRules and the given
+// additional rules. It returns nil for valid products.
+func (p *Product) Validate(rules ...ValidationRule) []error {
+	var problems []error
+	for _, rule := range append(DefaultValidationRules[:len(DefaultValidationRules):len(DefaultValidationRules)], rules...) {
+		problems = append(problems, rule(p)...)
+	}
+
+	return problems
+}
+
+// RequireFields returns a rule that reports the given fields, named by their
+// JSON name, if they are blank or empty.
+func RequireFields(fields ...string) ValidationRule {
+	return func(p *Product) []error {
+		var problems []error
+		value := reflect.ValueOf(p).Elem()
+		for _, field := range fields {
+			index, ok := productJSONFields[strings.ToLower(field)]
+			if !ok {
+				problems = append(problems, &ValidationError{Field: field, Message: "unknown field"})
+				continue
+			}
+
+			fieldValue := value.Field(index)
+			switch fieldValue.Kind() {
+			case reflect.String:
+				if strings.TrimSpace(fieldValue.String()) == "" {
+					problems = append(problems, &ValidationError{Field: field, Message: "is required"})
+				}
+			default:
+				if fieldValue.IsZero() || (fieldValue.Kind() == reflect.Slice && fieldValue.Len() == 0) {
+					problems = append(problems, &ValidationError{Field: field, Message: "is required"})
+				}
+			}
+		}
+
+		return problems
+	}
+}
+
+func validatePrice(p *Product) []error {
+	price := strings.TrimSpace(p.Price)
+	if price == "" {
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(strings.ReplaceAll(price, ",", "."), 64)
+	if err != nil {
+		return []error{&ValidationError{Field: "price", Message: "is not a number"}}
+	} else if number < 0 {
+		return []error{&ValidationError{Field: "price", Message: "is negative"}}
+	}
+
+	return nil
+}